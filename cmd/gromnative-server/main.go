@@ -0,0 +1,70 @@
+// Command gromnative-server serves the Net service over gRPC, so several processes can share
+// one HTTP client, connection pool and set of caches
+package main
+
+import (
+	"flag"
+	"github.com/ukparliament/gromnative/ext/net"
+	"github.com/ukparliament/gromnative/ext/processor"
+	"github.com/ukparliament/gromnative/ext/server"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"google.golang.org/grpc"
+	"log"
+	stdnet "net"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	listen := flag.String("listen", ":50051", "address to serve gRPC on")
+	connectTimeout := flag.Int64("connect-timeout", 0, "bound on dialing and the TLS handshake, in milliseconds")
+	readTimeout := flag.Int64("read-timeout", 0, "bound on the wait for response headers, in milliseconds")
+	timeout := flag.Int64("timeout", 0, "bound on each whole request, in milliseconds")
+	proxyURL := flag.String("proxy", "", "proxy URL for every request")
+	caFile := flag.String("ca-file", "", "PEM bundle of extra certificate authorities to trust")
+	certFile := flag.String("cert-file", "", "client certificate")
+	keyFile := flag.String("key-file", "", "client certificate key")
+	maxIdleConns := flag.Int("max-idle-connections", 0, "idle connections to keep per host")
+	cacheEntries := flag.Int("cache-entries", 0, "responses to cache, zero disables the response cache")
+	graphCacheBytes := flag.Int64("graph-cache-bytes", 0, "memory to use caching processed graphs, zero disables the graph cache")
+	flag.Parse()
+
+	err := net.Configure(&net.ClientConfig{
+		ConnectTimeout: *connectTimeout,
+		ReadTimeout:    *readTimeout,
+		Timeout:        *timeout,
+		ProxyURL:       *proxyURL,
+		CAFile:         *caFile,
+		CertFile:       *certFile,
+		KeyFile:        *keyFile,
+		MaxIdleConns:   *maxIdleConns,
+		CacheEntries:   *cacheEntries,
+	})
+	if err != nil {
+		log.Fatalf("Error configuring client: %v\n", err)
+	}
+	processor.ConfigureCache(*graphCacheBytes)
+
+	listener, err := stdnet.Listen("tcp", *listen)
+	if err != nil {
+		log.Fatalf("Error listening on %v: %v\n", *listen, err)
+	}
+
+	grpcServer := grpc.NewServer()
+	netType.RegisterNetServer(grpcServer, server.New())
+
+	// Finish in-flight requests before exiting
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-signals
+		log.Println("Stopping")
+		grpcServer.GracefulStop()
+	}()
+
+	log.Printf("Serving on %v\n", listener.Addr())
+	if err := grpcServer.Serve(listener); err != nil {
+		log.Fatalf("Error serving: %v\n", err)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+// #include <stdlib.h>
+import "C"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// call is a request started by start_get, running in the background until it finishes or is cancelled
+type call struct {
+	cancel   context.CancelFunc
+	done     chan struct{}
+	response Response
+	err      error
+}
+
+// calls holds started requests by handle until their responses are collected or they are cancelled
+var calls = struct {
+	sync.Mutex
+	next     int64
+	byHandle map[int64]*call
+}{byHandle: make(map[int64]*call)}
+
+// startCall runs request in the background, returning the handle used to collect or cancel it
+func startCall(request *Request) int64 {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &call{cancel: cancel, done: make(chan struct{})}
+
+	go func() {
+		defer close(c.done)
+		defer cancel()
+
+		c.response, c.err = GetandProcessContext(ctx, request)
+	}()
+
+	return addCall(c)
+}
+
+func addCall(c *call) int64 {
+	calls.Lock()
+	defer calls.Unlock()
+
+	calls.next++
+	calls.byHandle[calls.next] = c
+
+	return calls.next
+}
+
+// lookupCall returns the call for handle, removing it when remove is set
+func lookupCall(handle int64, remove bool) (*call, error) {
+	calls.Lock()
+	defer calls.Unlock()
+
+	c, ok := calls.byHandle[handle]
+	if !ok {
+		return nil, fmt.Errorf("Unknown request handle %v", handle)
+	}
+	if remove {
+		delete(calls.byHandle, handle)
+	}
+
+	return c, nil
+}
+
+// waitCall waits up to timeout for the call with handle to finish, or without limit when timeout is negative.
+// A finished call is forgotten once its response is returned, so later lookups of the handle fail.
+func waitCall(handle int64, timeout time.Duration) (*call, bool, error) {
+	c, err := lookupCall(handle, false)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Check for a finished call before starting the timer, as a select could pick an expired timer over it
+	select {
+	case <-c.done:
+	default:
+		if timeout < 0 {
+			<-c.done
+		} else {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+
+			select {
+			case <-c.done:
+			case <-timer.C:
+				return nil, false, nil
+			}
+		}
+	}
+
+	// The call may have been cancelled or collected by another caller while we waited, but its response is still ours
+	lookupCall(handle, true)
+
+	return c, true, nil
+}
+
+// cancelCall stops the call with handle and forgets it
+func cancelCall(handle int64) error {
+	c, err := lookupCall(handle, true)
+	if err != nil {
+		return err
+	}
+
+	c.cancel()
+
+	return nil
+}
+
+// collect is wait_get and poll_get, returning NULL while the call is still running
+func collect(handle int64, timeout time.Duration) *C.char {
+	c, done, err := waitCall(handle, timeout)
+	if err != nil {
+		return respond(Response{}, err)
+	}
+	if !done {
+		return nil
+	}
+
+	return respond(c.response, c.err)
+}
+
+// start_get begins get_request in the background, returning a handle for poll_get, wait_get and cancel_get
+//export start_get
+func start_get(data *C.char) C.longlong {
+	request := &Request{}
+	if err := json.Unmarshal([]byte(C.GoString(data)), request); err != nil {
+		errorResponse := Response{ Err: fmt.Sprintf("Error parsing request: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
+		log.Println(errorResponse.Err)
+
+		// Hand back a finished call so parse errors are collected like any other response
+		c := &call{cancel: func() {}, done: make(chan struct{}), response: errorResponse}
+		close(c.done)
+
+		return C.longlong(addCall(c))
+	}
+
+	return C.longlong(startCall(request))
+}
+
+// poll_get returns the response to a started request, or NULL while it is still running
+//export poll_get
+func poll_get(handle C.longlong) *C.char {
+	return collect(int64(handle), 0)
+}
+
+// wait_get waits up to timeout milliseconds, or without limit when negative, for the response to a started request,
+// returning NULL if it is still running
+//export wait_get
+func wait_get(handle C.longlong, timeout C.longlong) *C.char {
+	return collect(int64(handle), time.Duration(timeout)*time.Millisecond)
+}
+
+// cancel_get stops a started request, abandoning its HTTP call and processing. The handle is forgotten, so later
+// calls with it fail, though callers already waiting on it receive a response with a canceled error.
+//export cancel_get
+func cancel_get(handle C.longlong) {
+	if err := cancelCall(int64(handle)); err != nil {
+		log.Println(err)
+	}
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/ukparliament/gromnative/ext/processor"
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	"io/ioutil"
+	"log"
+	"testing"
+)
+
+// Compare the cost of passing a processed graph across the FFI boundary as JSON and as protobuf,
+// encoding on the Go side and decoding as a caller would, for full.nt and for 100 copies of it
+func BenchmarkResponseJSON(b *testing.B) {
+	for _, copies := range []int{1, 100} {
+		response := benchmarkResponse(b, copies)
+
+		b.Run(fmt.Sprintf("%dx", copies), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				data, err := json.Marshal(response)
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.SetBytes(int64(len(data)))
+
+				var decoded map[string]interface{}
+				if err := json.Unmarshal(data, &decoded); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkResponseProtobuf(b *testing.B) {
+	for _, copies := range []int{1, 100} {
+		response := benchmarkResponse(b, copies)
+
+		b.Run(fmt.Sprintf("%dx", copies), func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := 0; i < b.N; i++ {
+				data := respondProto(*response, nil)
+				b.SetBytes(int64(len(data)))
+
+				if err := proto.Unmarshal(data[4:], &graph.Response{}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// benchmarkResponse processes copies of full.nt, giving each copy its own subjects
+func benchmarkResponse(b *testing.B, copies int) *Response {
+	log.SetOutput(ioutil.Discard)
+
+	fixture, err := ioutil.ReadFile("../spec/fixtures/full.nt")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	var body bytes.Buffer
+	for i := 0; i < copies; i++ {
+		body.Write(bytes.Replace(fixture, []byte("https://id.parliament.uk/"), []byte(fmt.Sprintf("https://id.parliament.uk/%d/", i)), -1))
+	}
+
+	output, err := processor.Process(&processor.ProcessorInput{Body: body.Bytes()})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return &Response{
+		StatementsBySubject: output.StatementsBySubject,
+		EdgesBySubject:      output.EdgesBySubject,
+		StatusCode:          200,
+		Uri:                 "https://api.parliament.uk/query/person_index",
+	}
+}
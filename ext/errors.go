@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"github.com/ukparliament/gromnative/ext/net"
+	"github.com/ukparliament/gromnative/ext/processor"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+)
+
+// Kinds of error reported in Response.ErrorDetails
+const (
+	NetworkError        = net.NetworkError
+	TimeoutError        = net.TimeoutError
+	HTTPStatusError     = net.HTTPStatusError
+	LimitError          = net.LimitError
+	CanceledError       = net.CanceledError
+	ParseError          = "parse"
+	MarshalError        = "marshal"
+	InvalidRequestError = "invalid_request"
+)
+
+// Error describes a failure so callers can act on it without parsing the message
+type Error struct {
+	Kind       string `json:"kind"`
+	Message    string `json:"message"`
+	StatusCode int32  `json:"statusCode,omitempty"`
+	Uri        string `json:"uri,omitempty"`
+	Retryable  bool   `json:"retryable"`
+	// AttemptErrors holds the error from each failed attempt, in order, when the request was retried
+	AttemptErrors []string `json:"attemptErrors,omitempty"`
+}
+
+// getError describes a failure fetching a response
+func getError(output *netType.GetOutput) *Error {
+	return &Error{
+		Kind:          output.ErrorKind,
+		Message:       output.Error,
+		StatusCode:    output.StatusCode,
+		Uri:           output.Uri,
+		Retryable:     output.Retryable,
+		AttemptErrors: output.AttemptErrors,
+	}
+}
+
+// invalidResponse describes a request rejected before it was sent
+func invalidResponse(request *Request, err error) Response {
+	return Response{
+		Uri:          request.Uri,
+		Err:          err.Error(),
+		ErrorDetails: &Error{Kind: InvalidRequestError, Message: err.Error(), Uri: request.Uri},
+	}
+}
+
+// processError describes a failure processing a response body, which stops early once ctx is done
+func processError(ctx context.Context, output *processor.ProcessorOutput, uri string) *Error {
+	kind := ParseError
+	switch {
+	case output.ErrorCode != "":
+		kind = LimitError
+	case ctx.Err() == context.Canceled:
+		kind = CanceledError
+	case ctx.Err() == context.DeadlineExceeded:
+		kind = TimeoutError
+	}
+
+	return &Error{Kind: kind, Message: output.Error, Uri: uri}
+}
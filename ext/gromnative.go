@@ -1,55 +1,339 @@
 package main
 
+// #include <stdlib.h>
+import "C"
+
 import (
-	"C"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/ukparliament/gromnative/ext/net"
 	"github.com/ukparliament/gromnative/ext/processor"
-	. "github.com/ukparliament/gromnative/ext/types/net"
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"io"
 	"log"
+	"sync"
+	"unsafe"
 )
 
+// DefaultConcurrency is the number of requests a batch fetches at once when it does not say
+const DefaultConcurrency = 4
+
 type Response struct {
-	StatementsBySubject map[string][]processor.Triple  `json:"statementsBySubject"`
+	StatementsBySubject map[string][]graph.Triple      `json:"statementsBySubject"`
 	EdgesBySubject      map[string]map[string][]string `json:"edgesBySubject"`
+	SubjectsByType      [][]string                     `json:"subjectsByType,omitempty"`
 	StatusCode          int32                          `json:"statusCode"`
+	Attempts            int32                          `json:"attempts,omitempty"`
+	Cache               string                         `json:"cache,omitempty"`
 	Uri                 string                         `json:"uri"`
 	Err                 string                         `json:"error"`
+	// ErrorCode is body_too_large or too_many_triples when a limit is exceeded
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorDetails *Error `json:"errorDetails,omitempty"`
+	// Pages is the number of pages fetched when following pagination
+	Pages int32 `json:"pages,omitempty"`
+	// Results is set in place of the graph for SPARQL SELECT and ASK queries
+	Results *graph.Results `json:"results,omitempty"`
+}
+
+// Request is the envelope passed across the FFI boundary describing what to fetch
+type Request struct {
+	Uri     string                     `json:"uri"`
+	Headers []*netType.GetInput_Header `json:"headers"`
+	Filter  []string                   `json:"filter"`
+	// EdgeNaming is one of last_segment (the default), local_name, curie or full_uri
+	EdgeNaming string            `json:"edgeNaming"`
+	Prefixes   map[string]string `json:"prefixes"`
+	// Retry is optional, without it a single attempt is made
+	Retry *netType.GetInput_RetryPolicy `json:"retry"`
+	// Zero means no limit
+	MaxBodyBytes int64 `json:"maxBodyBytes"`
+	MaxTriples   int64 `json:"maxTriples"`
+	// AcceptedStatusCodes are treated as success, defaulting to net.DefaultAcceptedStatusCodes, along with
+	// net.DefaultWriteStatusCodes when a method other than GET is set
+	AcceptedStatusCodes []int32 `json:"acceptedStatusCodes"`
+	// Pagination is optional, without it only the URI given is fetched
+	Pagination *Pagination `json:"pagination"`
+	// Sparql, when given, is a query posted to the endpoint at Uri
+	Sparql *netType.GetInput_SparqlQuery `json:"sparql"`
+	// Method sends Body in place of a GET, such as a PUT to the graph API. It cannot be combined with
+	// Pagination or Sparql.
+	Method      string `json:"method"`
+	Body        string `json:"body"`
+	ContentType string `json:"contentType"`
+}
+
+// processingError marks an error from the processor as it passes back through net.Stream
+type processingError struct {
+	error
+}
+
+func (request *Request) getInput() *netType.GetInput {
+	return &netType.GetInput{
+		Uri:                 request.Uri,
+		Headers:             request.Headers,
+		Retry:               request.Retry,
+		MaxBodyBytes:        request.MaxBodyBytes,
+		MaxTriples:          request.MaxTriples,
+		AcceptedStatusCodes: request.AcceptedStatusCodes,
+		Sparql:              request.Sparql,
+	}
+}
+
+// netRequest is the request sent in place of getInput's when a method is set
+func (request *Request) netRequest() *netType.Request {
+	return &netType.Request{
+		Method:              request.Method,
+		Uri:                 request.Uri,
+		Headers:             request.Headers,
+		Body:                []byte(request.Body),
+		ContentType:         request.ContentType,
+		Retry:               request.Retry,
+		MaxBodyBytes:        request.MaxBodyBytes,
+		AcceptedStatusCodes: request.AcceptedStatusCodes,
+	}
+}
+
+// stream sends request as a GET, or a SPARQL query, unless it sets a method. A nil consume buffers the body.
+func (request *Request) stream(ctx context.Context, consume func(output *netType.GetOutput, body io.Reader) error) (*netType.GetOutput, error) {
+	if request.Method == "" {
+		return net.StreamContext(ctx, request.getInput(), consume)
+	}
+
+	return net.DoStreamContext(ctx, request.netRequest(), consume)
 }
 
-func GetandProcess(uri string) (Response, error) {
+// validate rejects requests setting options that cannot be sent together
+func (request *Request) validate() error {
+	if request.Method == "" {
+		return nil
+	}
+	if request.Pagination != nil {
+		return fmt.Errorf("Method %v cannot be combined with pagination", request.Method)
+	}
+	if request.Sparql != nil {
+		return fmt.Errorf("Method %v cannot be combined with a SPARQL query", request.Method)
+	}
+
+	return nil
+}
+
+// Config is passed across the FFI boundary to configure the HTTP client and caches
+type Config struct {
+	net.ClientConfig
+	// GraphCacheBytes enables a processed graph cache using up to roughly this much memory
+	GraphCacheBytes int64 `json:"graphCacheBytes"`
+}
+
+// BatchRequest is the envelope passed across the FFI boundary describing several fetches
+type BatchRequest struct {
+	Requests    []Request `json:"requests"`
+	Concurrency int       `json:"concurrency"`
+	// Merge processes every response into one graph, using the filter and edge naming below
+	// in place of those on each request
+	Merge      bool              `json:"merge"`
+	Filter     []string          `json:"filter"`
+	EdgeNaming string            `json:"edgeNaming"`
+	Prefixes   map[string]string `json:"prefixes"`
+	MaxTriples int64             `json:"maxTriples"`
+}
+
+func GetandProcess(request *Request) (Response, error) {
+	return GetandProcessContext(context.Background(), request)
+}
+
+// GetandProcessContext is GetandProcess, stopping the request and processing once ctx is done
+func GetandProcessContext(ctx context.Context, request *Request) (Response, error) {
+	if err := request.validate(); err != nil {
+		log.Printf("Invalid request: %v\n", err)
+		return invalidResponse(request, err), err
+	}
+
+	if request.Pagination != nil {
+		return getandProcessPages(ctx, request)
+	}
+
 	// Placeholder response object
-	response := Response{ Uri: uri }
+	response := Response{ Uri: request.Uri }
+
+	log.Printf("Requesting: %v\n", request.Uri)
 
-	log.Printf("Requesting: %v\n", uri)
+	// Decode the body as it arrives rather than holding all of it in memory
+	var processedData *processor.ProcessorOutput
+	requestResponse, err := request.stream(ctx, func(output *netType.GetOutput, body io.Reader) error {
+		var err error
+		processedData, err = processor.ProcessContext(ctx, &processor.ProcessorInput{
+			Reader:      body,
+			ContentType: output.ContentType,
+			Types:       request.Filter,
+			EdgeNaming:  processor.EdgeNaming(request.EdgeNaming),
+			Prefixes:    request.Prefixes,
+			MaxTriples:  request.MaxTriples,
+		})
+		if err != nil {
+			return processingError{err}
+		}
 
-	requestResponse, err := net.Get(&GetInput{Uri: uri})
+		return nil
+	})
 	if requestResponse.StatusCode != 0 {
 		response.StatusCode = requestResponse.StatusCode
 	}
+	response.Attempts = requestResponse.Attempts
+	response.Cache = requestResponse.Cache
+
+	if processErr, ok := err.(processingError); ok {
+		log.Printf("Error processing: %v\n", processErr.error)
+		response.Err = processedData.Error
+		response.ErrorCode = processedData.ErrorCode
+		response.ErrorDetails = processError(ctx, processedData, request.Uri)
+		return response, processErr.error
+	}
 
 	if err != nil {
 		log.Printf("Error getting: %v\n", err)
 		response.Err = requestResponse.Error
+		response.ErrorCode = requestResponse.ErrorCode
+		response.ErrorDetails = getError(requestResponse)
 		return response, err
 	}
 
-	processedData, err := processor.Process(&processor.ProcessorInput{Body: requestResponse.Body})
+	response.StatementsBySubject = processedData.StatementsBySubject
+	response.EdgesBySubject = processedData.EdgesBySubject
+	response.SubjectsByType = processedData.SubjectsByType
+	response.Results = processedData.Results
+
+	log.Println("Done")
+
+	return response, nil
+}
+
+// GetandProcessMany fetches requests with at most concurrency in flight, returning responses in request order
+func GetandProcessMany(requests []Request, concurrency int) []Response {
+	responses := make([]Response, len(requests))
+
+	inParallel(len(requests), concurrency, func(i int) {
+		response, err := GetandProcess(&requests[i])
+		if err != nil {
+			response.Err = fmt.Sprintf("Error getting data: %v\n", err)
+		}
+
+		responses[i] = response
+	})
+
+	return responses
+}
+
+// GetandMerge fetches every request in batch and processes the bodies into a single graph
+func GetandMerge(batch *BatchRequest) (Response, error) {
+	return GetandMergeContext(context.Background(), batch)
+}
+
+// GetandMergeContext is GetandMerge, stopping the requests and processing once ctx is done. The response
+// carries the attempts made across every request, and the URI and status code of the request that failed
+// or otherwise of the last request.
+func GetandMergeContext(ctx context.Context, batch *BatchRequest) (Response, error) {
+	for i := range batch.Requests {
+		if err := batch.Requests[i].validate(); err != nil {
+			log.Printf("Invalid request: %v\n", err)
+			return invalidResponse(&batch.Requests[i], err), err
+		}
+	}
+
+	response := Response{}
+
+	outputs := make([]*netType.GetOutput, len(batch.Requests))
+	errs := make([]error, len(batch.Requests))
+
+	inParallel(len(batch.Requests), batch.Concurrency, func(i int) {
+		request := batch.Requests[i]
+
+		log.Printf("Requesting: %v\n", request.Uri)
+		outputs[i], errs[i] = request.stream(ctx, nil)
+	})
+
+	for _, output := range outputs {
+		response.Attempts += output.Attempts
+	}
+
+	documents := make([]processor.Document, len(outputs))
+	for i, output := range outputs {
+		response.Uri = output.Uri
+		response.StatusCode = output.StatusCode
+
+		if errs[i] != nil {
+			log.Printf("Error getting: %v\n", errs[i])
+			response.Err = output.Error
+			response.ErrorCode = output.ErrorCode
+			response.ErrorDetails = getError(output)
+			return response, errs[i]
+		}
+
+		documents[i] = processor.Document{Body: output.Body, ContentType: output.ContentType}
+	}
+
+	processedData, err := processor.ProcessContext(ctx, &processor.ProcessorInput{
+		Documents:  documents,
+		Types:      batch.Filter,
+		EdgeNaming: processor.EdgeNaming(batch.EdgeNaming),
+		Prefixes:   batch.Prefixes,
+		MaxTriples: batch.MaxTriples,
+	})
 	if err != nil {
 		log.Printf("Error processing: %v\n", err)
+
+		// Point at the response that could not be decoded when there is one
+		if failed, ok := err.(*processor.DocumentError); ok {
+			response.Uri = outputs[failed.Index].Uri
+			response.StatusCode = outputs[failed.Index].StatusCode
+		}
+
 		response.Err = processedData.Error
+		response.ErrorCode = processedData.ErrorCode
+		response.ErrorDetails = processError(ctx, processedData, response.Uri)
 		return response, err
 	}
 
 	response.StatementsBySubject = processedData.StatementsBySubject
 	response.EdgesBySubject = processedData.EdgesBySubject
+	response.SubjectsByType = processedData.SubjectsByType
+	response.Results = processedData.Results
 
 	log.Println("Done")
 
 	return response, nil
 }
 
+// inParallel calls work for each index below count, running at most concurrency calls at once
+func inParallel(count int, concurrency int, work func(int)) {
+	if concurrency < 1 {
+		concurrency = DefaultConcurrency
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for worker := 0; worker < concurrency && worker < count; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				work(i)
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+}
+
 func cStringConversion(response *Response) *C.char {
 	json, err := json.Marshal(response)
 	if err != nil {
@@ -59,24 +343,118 @@ func cStringConversion(response *Response) *C.char {
 	return C.CString(string(json))
 }
 
+func handle(request *Request) *C.char {
+	return respond(GetandProcess(request))
+}
+
+func respond(response Response, err error) *C.char {
+	if err != nil {
+		response.Err = fmt.Sprintf("Error getting data: %v\n", err)
+		log.Println(response.Err)
+		return cStringConversion(&response)
+	}
+
+	responseJson, err := json.Marshal(response)
+	if err != nil {
+		errorResponse := &Response{ Err: fmt.Sprintf("Error marshalling data: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
+		log.Println(errorResponse.Err)
+		return cStringConversion(errorResponse)
+	}
+
+	return C.CString(string(responseJson))
+}
+
 //export get
 func get(data *C.char) *C.char {
-	uri := C.GoString(data)
-	response, err := GetandProcess(uri)
-	if err != nil {
-		errorResponse := &Response{ Err: fmt.Sprintf("Error getting data: %v\n", err) }
+	return handle(&Request{Uri: C.GoString(data)})
+}
+
+//export get_request
+func get_request(data *C.char) *C.char {
+	request := &Request{}
+	if err := json.Unmarshal([]byte(C.GoString(data)), request); err != nil {
+		errorResponse := &Response{ Err: fmt.Sprintf("Error parsing request: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
 		log.Println(errorResponse.Err)
 		return cStringConversion(errorResponse)
 	}
 
-	responseJson, err := json.Marshal(response)
+	return handle(request)
+}
+
+// get_request_proto is get_request returning a length prefixed graph.Response, see lengthPrefixed
+//export get_request_proto
+func get_request_proto(data *C.char) unsafe.Pointer {
+	request := &Request{}
+	if err := json.Unmarshal([]byte(C.GoString(data)), request); err != nil {
+		errorResponse := Response{ Err: fmt.Sprintf("Error parsing request: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
+		log.Println(errorResponse.Err)
+		return C.CBytes(respondProto(errorResponse, nil))
+	}
+
+	return C.CBytes(respondProto(GetandProcess(request)))
+}
+
+//export get_many
+func get_many(data *C.char) *C.char {
+	batch := &BatchRequest{}
+	if err := json.Unmarshal([]byte(C.GoString(data)), batch); err != nil {
+		errorResponse := &Response{ Err: fmt.Sprintf("Error parsing request: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
+		log.Println(errorResponse.Err)
+		return cStringConversion(errorResponse)
+	}
+
+	if batch.Merge {
+		return respond(GetandMerge(batch))
+	}
+
+	responsesJson, err := json.Marshal(GetandProcessMany(batch.Requests, batch.Concurrency))
 	if err != nil {
 		errorResponse := &Response{ Err: fmt.Sprintf("Error marshalling data: %v\n", err) }
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
 		log.Println(errorResponse.Err)
 		return cStringConversion(errorResponse)
 	}
 
-	return C.CString(string(responseJson))
+	return C.CString(string(responsesJson))
+}
+
+//export configure_client
+func configure_client(data *C.char) *C.char {
+	config := &Config{}
+	err := json.Unmarshal([]byte(C.GoString(data)), config)
+	if err == nil {
+		err = net.Configure(&config.ClientConfig)
+	}
+	if err == nil {
+		processor.ConfigureCache(config.GraphCacheBytes)
+	}
+
+	result := ""
+	if err != nil {
+		result = fmt.Sprintf("Error configuring client: %v\n", err)
+		log.Println(result)
+	}
+
+	resultJson, _ := json.Marshal(map[string]string{"error": result})
+
+	return C.CString(string(resultJson))
+}
+
+//export cache_stats
+func cache_stats() *C.char {
+	statsJson, _ := json.Marshal(processor.CacheStatistics())
+
+	return C.CString(string(statsJson))
+}
+
+// gromnative_free releases a string returned by any of the exports, which callers own once it is returned
+//export gromnative_free
+func gromnative_free(data *C.char) {
+	C.free(unsafe.Pointer(data))
 }
 
 func main() {}
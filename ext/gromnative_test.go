@@ -1,13 +1,22 @@
 package main
 
 import (
+  "context"
+  "encoding/binary"
+  "errors"
+  "fmt"
+  "github.com/golang/protobuf/proto"
   . "github.com/onsi/ginkgo"
   . "github.com/onsi/gomega"
   "github.com/ukparliament/gromnative/ext/processor"
+  "github.com/ukparliament/gromnative/ext/types/graph"
+  netType "github.com/ukparliament/gromnative/ext/types/net"
   "gopkg.in/jarcoal/httpmock.v1"
   "io/ioutil"
   "log"
+  "net/http"
   "testing"
+  "time"
 )
 
 func TestProcessor(t *testing.T) {
@@ -47,37 +56,37 @@ var _ = Describe("gromnative", func() {
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#type",
-            Object: "<https://id.parliament.uk/schema/Person>",
+            Object: processor.NewIRI("https://id.parliament.uk/schema/Person").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "https://id.parliament.uk/schema/personGivenName",
-            Object: "\"Diane\"^^<xsd:string>",
+            Object: processor.NewLiteral("Diane", "", "").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "https://id.parliament.uk/schema/personOtherNames",
-            Object: "\"Julie\"^^<xsd:string>",
+            Object: processor.NewLiteral("Julie", "", "").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "https://id.parliament.uk/schema/personFamilyName",
-            Object: "\"Abbott\"^^<xsd:string>",
+            Object: processor.NewLiteral("Abbott", "", "").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "http://example.com/F31CBD81AD8343898B49DC65743F0BDF",
-            Object: "\"Ms Diane Abbott\"^^<xsd:string>",
+            Object: processor.NewLiteral("Ms Diane Abbott", "", "").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "http://example.com/D79B0BAC513C4A9A87C9D5AFF1FC632F",
-            Object: "\"Rt Hon Diane Abbott MP\"^^<xsd:string>",
+            Object: processor.NewLiteral("Rt Hon Diane Abbott MP", "", "").Proto(),
           },
           processor.Triple {
             Subject: "https://id.parliament.uk/43RHonMf",
             Predicate: "https://id.parliament.uk/schema/Test",
-            Object: "<https://id.parliament.uk/12345678>",
+            Object: processor.NewIRI("https://id.parliament.uk/12345678").Proto(),
           })
         edgesBySubject := make(map[string]map[string][]string)
         edgesBySubject["https://id.parliament.uk/43RHonMf"] = make(map[string][]string)
@@ -89,17 +98,84 @@ var _ = Describe("gromnative", func() {
           StatementsBySubject: statementsBySubject,
           EdgesBySubject: edgesBySubject,
           StatusCode: 200,
+          Attempts: 1,
           Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
           Err: "",
         }
 
-        res, err := GetandProcess("https://api.parliament.uk/query/person_by_id?person_id=43RHonMf")
+        res, err := GetandProcess(&Request{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
         Expect(res).To(Equal(expected))
         Expect(err).NotTo(HaveOccurred())
       })
     })
 
+    Context("with headers", func() {
+      BeforeEach(func() {
+        httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+          func(req *http.Request) (*http.Response, error) {
+            if req.Header.Get("Api-Access-Key") != "12345678" {
+              return httpmock.NewStringResponse(401, "Unauthorized"), nil
+            }
+
+            return httpmock.NewStringResponse(200, ""), nil
+          })
+      })
+
+      It("passes the headers to the request", func() {
+        request := &Request{
+          Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+          Headers: []*netType.GetInput_Header{{Key: "Api-Access-Key", Value: "12345678"}},
+        }
+
+        res, err := GetandProcess(request)
+
+        Expect(res.StatusCode).To(Equal(int32(200)))
+        Expect(err).NotTo(HaveOccurred())
+      })
+    })
+
+    Context("with a 204 response", func() {
+      BeforeEach(func() {
+        httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_index",
+          httpmock.NewStringResponder(204, ""))
+      })
+
+      It("returns an empty graph", func() {
+        res, err := GetandProcess(&Request{Uri: "https://api.parliament.uk/query/person_index"})
+
+        Expect(res.StatusCode).To(Equal(int32(204)))
+        Expect(res.StatementsBySubject).To(BeEmpty())
+        Expect(res.Err).To(BeEmpty())
+        Expect(err).NotTo(HaveOccurred())
+      })
+    })
+
+    Context("with a triple limit", func() {
+      BeforeEach(func() {
+        fixture, _ := ioutil.ReadFile("../spec/fixtures/full.nt")
+
+        httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+          httpmock.NewStringResponder(200, string(fixture)))
+      })
+
+      It("returns the limit's error code", func() {
+        res, err := GetandProcess(&Request{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf", MaxTriples: 10})
+
+        Expect(res.ErrorCode).To(Equal(processor.TooManyTriples))
+        Expect(res.ErrorDetails.Kind).To(Equal(LimitError))
+        Expect(res.StatementsBySubject).To(BeNil())
+        Expect(err).To(MatchError("Found more than the limit of 10 triples"))
+      })
+
+      It("returns the body limit's error code", func() {
+        res, err := GetandProcess(&Request{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf", MaxBodyBytes: 100})
+
+        Expect(res.ErrorCode).To(Equal("body_too_large"))
+        Expect(err).To(HaveOccurred())
+      })
+    })
+
     Context("with an error getting", func() {
       BeforeEach(func() {
         httpmock.DeactivateAndReset()
@@ -114,11 +190,19 @@ var _ = Describe("gromnative", func() {
           StatementsBySubject: nil,
           EdgesBySubject: nil,
           StatusCode: 0,
+          Attempts: 1,
           Uri: "foo://a_broken.url",
           Err: "Get foo://a_broken.url: unsupported protocol scheme \"foo\"",
+          ErrorDetails: &Error{
+            Kind: NetworkError,
+            Message: "Get foo://a_broken.url: unsupported protocol scheme \"foo\"",
+            Uri: "foo://a_broken.url",
+            Retryable: false,
+            AttemptErrors: []string{"Get foo://a_broken.url: unsupported protocol scheme \"foo\""},
+          },
         }
 
-        res, err := GetandProcess("foo://a_broken.url")
+        res, err := GetandProcess(&Request{Uri: "foo://a_broken.url"})
 
         Expect(res).To(Equal(expected))
         Expect(err.Error()).To(Equal("Get foo://a_broken.url: unsupported protocol scheme \"foo\""))
@@ -136,15 +220,509 @@ var _ = Describe("gromnative", func() {
           StatementsBySubject: nil,
           EdgesBySubject: nil,
           StatusCode: 200,
+          Attempts: 1,
           Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
           Err: "lenient parsing: line 1: invalid subject in {\"error\":\"Definitely not Triples\"}",
+          ErrorDetails: &Error{
+            Kind: ParseError,
+            Message: "lenient parsing: line 1: invalid subject in {\"error\":\"Definitely not Triples\"}",
+            Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+          },
         }
 
-        res, err := GetandProcess("https://api.parliament.uk/query/person_by_id?person_id=43RHonMf")
+        res, err := GetandProcess(&Request{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
         Expect(res).To(Equal(expected))
         Expect(err.Error()).To(Equal("lenient parsing: line 1: invalid subject in {\"error\":\"Definitely not Triples\"}"))
       })
     })
+
+    Context("with retries", func() {
+      BeforeEach(func() {
+        httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_index",
+          httpmock.NewStringResponder(503, "Unavailable"))
+      })
+
+      It("reports the error from each attempt", func() {
+        res, err := GetandProcess(&Request{
+          Uri: "https://api.parliament.uk/query/person_index",
+          Retry: &netType.GetInput_RetryPolicy{MaxAttempts: 2, BackoffBase: 1},
+        })
+
+        Expect(err).To(HaveOccurred())
+        Expect(res.Attempts).To(Equal(int32(2)))
+        Expect(res.ErrorDetails.AttemptErrors).To(Equal([]string{
+          "Received 503 status code from https://api.parliament.uk/query/person_index: Unavailable",
+          "Received 503 status code from https://api.parliament.uk/query/person_index: Unavailable",
+        }))
+      })
+    })
+  })
+
+  Describe("GetandProcessMany", func() {
+    BeforeEach(func() {
+      fixture, _ := ioutil.ReadFile("../spec/fixtures/one_edge.nt")
+
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+        httpmock.NewStringResponder(200, string(fixture)))
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_index",
+        httpmock.NewStringResponder(500, "Error"))
+    })
+
+    It("returns the responses in request order with per-item errors", func() {
+      requests := []Request{
+        {Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"},
+        {Uri: "https://api.parliament.uk/query/person_index"},
+        {Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"},
+      }
+
+      res := GetandProcessMany(requests, 2)
+
+      Expect(res).To(HaveLen(3))
+      Expect(res[0].Uri).To(Equal("https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"))
+      Expect(res[0].StatementsBySubject).To(HaveKey("https://id.parliament.uk/43RHonMf"))
+      Expect(res[0].Err).To(BeEmpty())
+      Expect(res[1].Uri).To(Equal("https://api.parliament.uk/query/person_index"))
+      Expect(res[1].StatusCode).To(Equal(int32(500)))
+      Expect(res[1].ErrorDetails).To(Equal(&Error{
+        Kind: HTTPStatusError,
+        Message: "Received 500 status code from https://api.parliament.uk/query/person_index: Error",
+        StatusCode: 500,
+        Uri: "https://api.parliament.uk/query/person_index",
+        AttemptErrors: []string{"Received 500 status code from https://api.parliament.uk/query/person_index: Error"},
+      }))
+      Expect(res[1].Err).To(Equal("Error getting data: Received 500 status code from https://api.parliament.uk/query/person_index: Error\n"))
+      Expect(res[2].StatementsBySubject).To(HaveKey("https://id.parliament.uk/43RHonMf"))
+    })
+
+    It("returns no responses for no requests", func() {
+      Expect(GetandProcessMany([]Request{}, 0)).To(BeEmpty())
+    })
+  })
+
+  Describe("GetandMerge", func() {
+    BeforeEach(func() {
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+        httpmock.NewStringResponder(200, "<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> \"Diane\" .\n<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personHasGenderIdentity> <https://id.parliament.uk/SPRKaz3b> ."))
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/gender_by_id?gender_id=SPRKaz3b",
+        httpmock.NewStringResponder(200, "<https://id.parliament.uk/SPRKaz3b> <https://id.parliament.uk/schema/genderName> \"Female\" .\n<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> \"Diane\" ."))
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/person_index",
+        httpmock.NewStringResponder(500, "Error"))
+    })
+
+    It("merges the responses into a single graph", func() {
+      res, err := GetandMerge(&BatchRequest{
+        Requests: []Request{
+          {Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"},
+          {Uri: "https://api.parliament.uk/query/gender_by_id?gender_id=SPRKaz3b"},
+        },
+      })
+
+      Expect(res.StatementsBySubject).To(HaveLen(2))
+      Expect(res.StatementsBySubject["https://id.parliament.uk/43RHonMf"]).To(HaveLen(2))
+      Expect(res.EdgesBySubject["https://id.parliament.uk/43RHonMf"]["personHasGenderIdentity"]).To(Equal([]string{"https://id.parliament.uk/SPRKaz3b"}))
+      Expect(err).NotTo(HaveOccurred())
+    })
+
+    It("returns the first error", func() {
+      res, err := GetandMerge(&BatchRequest{
+        Requests: []Request{
+          {Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"},
+          {Uri: "https://api.parliament.uk/query/person_index"},
+        },
+      })
+
+      Expect(res.Uri).To(Equal("https://api.parliament.uk/query/person_index"))
+      Expect(res.StatusCode).To(Equal(int32(500)))
+      Expect(err).To(MatchError("Received 500 status code from https://api.parliament.uk/query/person_index: Error"))
+      Expect(res.Attempts).To(Equal(int32(2)))
+    })
+
+    It("points at the response that could not be decoded", func() {
+      httpmock.RegisterResponder("GET", "https://api.parliament.uk/query/broken", httpmock.NewStringResponder(200, "<https://id.parliament.uk/1> broken"))
+
+      res, err := GetandMerge(&BatchRequest{
+        Requests: []Request{
+          {Uri: "https://api.parliament.uk/query/broken"},
+          {Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"},
+        },
+      })
+
+      Expect(err).To(BeAssignableToTypeOf(&processor.DocumentError{}))
+      Expect(res.Uri).To(Equal("https://api.parliament.uk/query/broken"))
+      Expect(res.StatusCode).To(Equal(int32(200)))
+      Expect(res.ErrorDetails.Kind).To(Equal(ParseError))
+      Expect(res.ErrorDetails.Uri).To(Equal("https://api.parliament.uk/query/broken"))
+    })
+
+    It("stops once the context is done", func() {
+      ctx, cancel := context.WithCancel(context.Background())
+      cancel()
+
+      res, err := GetandMergeContext(ctx, &BatchRequest{
+        Requests: []Request{{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"}},
+      })
+
+      Expect(err).To(HaveOccurred())
+      Expect(res.ErrorDetails.Kind).To(Equal(CanceledError))
+    })
+  })
+
+  Describe("GetandProcess with a SPARQL query", func() {
+    endpoint := "http://localhost:7200/repositories/parliament"
+
+    BeforeEach(func() {
+      httpmock.RegisterResponder("POST", endpoint, func(req *http.Request) (*http.Response, error) {
+        if req.FormValue("query") != "DESCRIBE <https://id.parliament.uk/43RHonMf>" {
+          return httpmock.NewStringResponse(400, "Bad query"), nil
+        }
+
+        resp := httpmock.NewStringResponse(200, `@prefix schema: <https://id.parliament.uk/schema/> .
+<https://id.parliament.uk/43RHonMf> a schema:Person ; schema:personGivenName "Diane" .`)
+        resp.Header.Set("Content-Type", "text/turtle")
+
+        return resp, nil
+      })
+    })
+
+    It("processes the graph returned", func() {
+      res, err := GetandProcess(&Request{
+        Uri: endpoint,
+        Filter: []string{"https://id.parliament.uk/schema/Person"},
+        Sparql: &netType.GetInput_SparqlQuery{Query: "DESCRIBE <https://id.parliament.uk/43RHonMf>"},
+      })
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatusCode).To(Equal(int32(200)))
+      Expect(res.StatementsBySubject["https://id.parliament.uk/43RHonMf"]).To(HaveLen(2))
+      Expect(res.SubjectsByType).To(Equal([][]string{{"https://id.parliament.uk/43RHonMf"}}))
+    })
+
+    It("returns the results of a SELECT query", func() {
+      httpmock.RegisterResponder("POST", endpoint, func(req *http.Request) (*http.Response, error) {
+        resp := httpmock.NewStringResponse(200, `{ "head": { "vars": ["name"] }, "results": { "bindings": [{ "name": { "type": "literal", "value": "Diane" } }] } }`)
+        resp.Header.Set("Content-Type", "application/sparql-results+json")
+
+        return resp, nil
+      })
+
+      res, err := GetandProcess(&Request{Uri: endpoint, Sparql: &netType.GetInput_SparqlQuery{Query: "SELECT ?name WHERE { ?person <https://id.parliament.uk/schema/personGivenName> ?name }"}})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Results.Variables).To(Equal([]string{"name"}))
+      Expect(res.Results.Rows[0].Bindings["name"].Value).To(Equal("Diane"))
+      Expect(res.StatementsBySubject).To(BeEmpty())
+    })
+
+    It("returns the endpoint's error", func() {
+      res, err := GetandProcess(&Request{Uri: endpoint, Sparql: &netType.GetInput_SparqlQuery{Query: "DESCRIBE"}})
+
+      Expect(err).To(MatchError("Received 400 status code from " + endpoint + ": Bad query"))
+      Expect(res.ErrorDetails.Kind).To(Equal(HTTPStatusError))
+    })
+  })
+
+  Describe("GetandProcess with a method", func() {
+    uri := "https://api.parliament.uk/graph/43RHonMf"
+    body := `<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .`
+
+    It("sends the body and processes the graph returned", func() {
+      httpmock.RegisterResponder("PUT", uri, func(req *http.Request) (*http.Response, error) {
+        received, _ := ioutil.ReadAll(req.Body)
+        if string(received) != body || req.Header.Get("Content-Type") != "application/n-triples" {
+          return httpmock.NewStringResponse(400, "Bad request"), nil
+        }
+
+        return httpmock.NewStringResponse(201, body), nil
+      })
+
+      res, err := GetandProcess(&Request{Uri: uri, Method: "PUT", Body: body, ContentType: "application/n-triples"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatusCode).To(Equal(int32(201)))
+      Expect(res.StatementsBySubject["https://id.parliament.uk/43RHonMf"]).To(HaveLen(1))
+    })
+
+    It("accepts responses without a body", func() {
+      httpmock.RegisterResponder("DELETE", uri, httpmock.NewStringResponder(204, ""))
+
+      res, err := GetandProcess(&Request{Uri: uri, Method: "DELETE"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatusCode).To(Equal(int32(204)))
+      Expect(res.StatementsBySubject).To(BeEmpty())
+    })
+
+    It("returns the server's error", func() {
+      httpmock.RegisterResponder("POST", uri, httpmock.NewStringResponder(409, "Conflict"))
+
+      res, err := GetandProcess(&Request{Uri: uri, Method: "POST", Body: body})
+
+      Expect(err).To(MatchError("Received 409 status code from " + uri + ": Conflict"))
+      Expect(res.ErrorDetails.Kind).To(Equal(HTTPStatusError))
+      Expect(res.ErrorDetails.StatusCode).To(Equal(int32(409)))
+    })
+
+    It("cannot be combined with pagination", func() {
+      res, err := GetandProcess(&Request{Uri: uri, Method: "POST", Body: body, Pagination: &Pagination{}})
+
+      Expect(err).To(MatchError("Method POST cannot be combined with pagination"))
+      Expect(res.Uri).To(Equal(uri))
+      Expect(res.ErrorDetails.Kind).To(Equal(InvalidRequestError))
+    })
+
+    It("cannot be combined with a SPARQL query", func() {
+      res, err := GetandProcess(&Request{Uri: uri, Method: "POST", Sparql: &netType.GetInput_SparqlQuery{Query: "DESCRIBE <https://id.parliament.uk/43RHonMf>"}})
+
+      Expect(err).To(MatchError("Method POST cannot be combined with a SPARQL query"))
+      Expect(res.ErrorDetails.Kind).To(Equal(InvalidRequestError))
+    })
+
+    It("cannot be combined with a SPARQL query when merging", func() {
+      res, err := GetandMerge(&BatchRequest{
+        Requests: []Request{{Uri: uri, Method: "POST", Sparql: &netType.GetInput_SparqlQuery{Query: "DESCRIBE <https://id.parliament.uk/43RHonMf>"}}},
+      })
+
+      Expect(err).To(MatchError("Method POST cannot be combined with a SPARQL query"))
+      Expect(res.ErrorDetails.Kind).To(Equal(InvalidRequestError))
+    })
+  })
+
+  Describe("GetandProcess with pagination", func() {
+    index := "https://api.parliament.uk/query/person_index"
+
+    page := func(uri string, body string, link string) {
+      httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+        resp := httpmock.NewStringResponse(200, body)
+        if link != "" {
+          resp.Header.Set("Link", link)
+        }
+
+        return resp, nil
+      })
+    }
+
+    It("follows Link headers and merges the pages", func() {
+      page(index, `<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .`, `<?page=2>; rel="next"`)
+      page(index+"?page=2", `<https://id.parliament.uk/2> <https://id.parliament.uk/schema/name> "Two" .`, "")
+
+      res, err := GetandProcess(&Request{Uri: index, Pagination: &Pagination{}})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Pages).To(Equal(int32(2)))
+      Expect(res.Attempts).To(Equal(int32(2)))
+      Expect(res.StatementsBySubject).To(HaveKey("https://id.parliament.uk/1"))
+      Expect(res.StatementsBySubject).To(HaveKey("https://id.parliament.uk/2"))
+    })
+
+    It("follows hydra:next triples", func() {
+      page(index, `<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .
+<https://api.parliament.uk/query/person_index> <http://www.w3.org/ns/hydra/core#next> <https://api.parliament.uk/query/person_index?page=2> .`, "")
+      page(index+"?page=2", `<https://id.parliament.uk/2> <https://id.parliament.uk/schema/name> "Two" .`, "")
+
+      res, err := GetandProcess(&Request{Uri: index, Pagination: &Pagination{}})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Pages).To(Equal(int32(2)))
+      Expect(res.StatementsBySubject).To(HaveKey("https://id.parliament.uk/2"))
+    })
+
+    It("stops at the maximum number of pages", func() {
+      page(index, `<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .`, `<?page=2>; rel="next"`)
+      page(index+"?page=2", `<https://id.parliament.uk/2> <https://id.parliament.uk/schema/name> "Two" .`, "")
+
+      res, err := GetandProcess(&Request{Uri: index, Pagination: &Pagination{MaxPages: 1}})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Pages).To(Equal(int32(1)))
+      Expect(res.StatementsBySubject).NotTo(HaveKey("https://id.parliament.uk/2"))
+    })
+
+    It("stops at a page it has already fetched", func() {
+      page(index, `<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .`, `<?page=2>; rel="next"`)
+      page(index+"?page=2", `<https://id.parliament.uk/2> <https://id.parliament.uk/schema/name> "Two" .`, `<person_index>; rel="next"`)
+
+      res, err := GetandProcess(&Request{Uri: index, Pagination: &Pagination{}})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Pages).To(Equal(int32(2)))
+    })
+
+    It("returns the error from a page that fails", func() {
+      page(index, `<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .`, `<?page=2>; rel="next"`)
+      httpmock.RegisterResponder("GET", index+"?page=2", httpmock.NewStringResponder(500, "Error"))
+
+      res, err := GetandProcess(&Request{Uri: index, Pagination: &Pagination{}})
+
+      Expect(err).To(MatchError("Received 500 status code from " + index + "?page=2: Error"))
+      Expect(res.Pages).To(Equal(int32(1)))
+      Expect(res.StatusCode).To(Equal(int32(500)))
+      Expect(res.ErrorDetails.Uri).To(Equal(index + "?page=2"))
+      Expect(res.StatementsBySubject).To(BeNil())
+    })
+  })
+
+  Describe("started calls", func() {
+    uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+    var release chan struct{}
+
+    BeforeEach(func() {
+      release = make(chan struct{})
+      httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+        select {
+        case <-release:
+          return httpmock.NewStringResponse(200, `<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .`), nil
+        case <-req.Context().Done():
+          return nil, req.Context().Err()
+        }
+      })
+    })
+
+    It("returns nothing while the request is running", func() {
+      handle := startCall(&Request{Uri: uri})
+
+      c, done, err := waitCall(handle, 0)
+
+      Expect(c).To(BeNil())
+      Expect(done).To(BeFalse())
+      Expect(err).NotTo(HaveOccurred())
+
+      close(release)
+      _, done, _ = waitCall(handle, -1)
+      Expect(done).To(BeTrue())
+    })
+
+    It("returns a finished call without waiting", func() {
+      for i := 0; i < 100; i++ {
+        c := &call{cancel: func() {}, done: make(chan struct{})}
+        close(c.done)
+        handle := addCall(c)
+
+        _, done, err := waitCall(handle, 0)
+
+        Expect(done).To(BeTrue())
+        Expect(err).NotTo(HaveOccurred())
+      }
+    })
+
+    It("returns the response once, when it is ready", func() {
+      handle := startCall(&Request{Uri: uri})
+      close(release)
+
+      c, done, err := waitCall(handle, -1)
+
+      Expect(done).To(BeTrue())
+      Expect(err).NotTo(HaveOccurred())
+      Expect(c.err).NotTo(HaveOccurred())
+      Expect(c.response.StatementsBySubject).To(HaveKey("https://id.parliament.uk/43RHonMf"))
+
+      _, _, err = waitCall(handle, 0)
+      Expect(err).To(MatchError(fmt.Sprintf("Unknown request handle %v", handle)))
+    })
+
+    It("stops the request when cancelled", func() {
+      handle := startCall(&Request{Uri: uri})
+
+      calls.Lock()
+      c := calls.byHandle[handle]
+      calls.Unlock()
+
+      Expect(cancelCall(handle)).To(Succeed())
+      Eventually(c.done).Should(BeClosed())
+
+      Expect(c.err).To(HaveOccurred())
+      Expect(c.response.ErrorDetails.Kind).To(Equal(CanceledError))
+      Expect(cancelCall(handle)).To(HaveOccurred())
+    })
+
+    It("hands the cancelled response to callers already waiting", func() {
+      handle := startCall(&Request{Uri: uri})
+      waited := make(chan *call)
+
+      go func() {
+        defer GinkgoRecover()
+
+        c, done, err := waitCall(handle, -1)
+        Expect(done).To(BeTrue())
+        Expect(err).NotTo(HaveOccurred())
+
+        waited <- c
+      }()
+
+      // Give the waiter time to look the call up before it is cancelled
+      time.Sleep(50 * time.Millisecond)
+      Expect(cancelCall(handle)).To(Succeed())
+
+      var c *call
+      Eventually(waited).Should(Receive(&c))
+      Expect(c.response.ErrorDetails.Kind).To(Equal(CanceledError))
+
+      _, _, err := waitCall(handle, 0)
+      Expect(err).To(MatchError(fmt.Sprintf("Unknown request handle %v", handle)))
+    })
+  })
+
+  Describe("respondProto", func() {
+    decode := func(data []byte) *graph.Response {
+      Expect(binary.BigEndian.Uint32(data)).To(Equal(uint32(len(data) - 4)))
+
+      response := &graph.Response{}
+      Expect(proto.Unmarshal(data[4:], response)).To(Succeed())
+
+      return response
+    }
+
+    It("encodes the graph and response details", func() {
+      response := Response{
+        StatementsBySubject: map[string][]graph.Triple{
+          "https://id.parliament.uk/1": {{Subject: "https://id.parliament.uk/1", Predicate: "https://id.parliament.uk/schema/name", Object: processor.NewLiteral("A", "", "").Proto()}},
+        },
+        EdgesBySubject: map[string]map[string][]string{
+          "https://id.parliament.uk/1": {"partyMembership": {"https://id.parliament.uk/2"}},
+        },
+        StatusCode: 200,
+        Attempts: 1,
+        Uri: "https://api.parliament.uk/query/person_index",
+      }
+
+      res := decode(respondProto(response, nil))
+
+      Expect(res.StatusCode).To(Equal(int32(200)))
+      Expect(res.Uri).To(Equal("https://api.parliament.uk/query/person_index"))
+      Expect(res.Graph.StatementsBySubject["https://id.parliament.uk/1"].Triples[0].Object.Value).To(Equal("A"))
+      Expect(res.Graph.EdgesBySubject["https://id.parliament.uk/1"].Edges["partyMembership"].Uris).To(Equal([]string{"https://id.parliament.uk/2"}))
+      Expect(res.Error).To(BeEmpty())
+    })
+
+    It("encodes errors", func() {
+      response := Response{ErrorDetails: &Error{Kind: HTTPStatusError, Message: "Received 500", StatusCode: 500}}
+
+      res := decode(respondProto(response, errors.New("Received 500")))
+
+      Expect(res.Error).To(Equal("Error getting data: Received 500\n"))
+      Expect(res.ErrorDetails.Kind).To(Equal("http_status"))
+      Expect(res.ErrorDetails.StatusCode).To(Equal(int32(500)))
+    })
+
+    It("keeps the details of a failed response", func() {
+      response := Response{
+        StatusCode: 503,
+        Attempts: 2,
+        Uri: "https://api.parliament.uk/query/person_index",
+        Cache: "miss",
+        Pages: 1,
+        ErrorDetails: &Error{Kind: HTTPStatusError, Message: "Received 503", StatusCode: 503, AttemptErrors: []string{"Received 503", "Received 503"}},
+      }
+
+      res := decode(respondProto(response, errors.New("Received 503")))
+
+      Expect(res.Error).To(Equal("Error getting data: Received 503\n"))
+      Expect(res.StatusCode).To(Equal(int32(503)))
+      Expect(res.Attempts).To(Equal(int32(2)))
+      Expect(res.Uri).To(Equal("https://api.parliament.uk/query/person_index"))
+      Expect(res.Cache).To(Equal("miss"))
+      Expect(res.Pages).To(Equal(int32(1)))
+      Expect(res.ErrorDetails.AttemptErrors).To(Equal([]string{"Received 503", "Received 503"}))
+    })
   })
 })
@@ -0,0 +1,235 @@
+package net
+
+import (
+	"container/list"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// CacheHit means the response was served from the cache without a request
+	CacheHit = "hit"
+	// CacheRevalidated means a conditional request confirmed the cached response was unchanged
+	CacheRevalidated = "revalidated"
+	// CacheMiss means the response came from the network
+	CacheMiss = "miss"
+)
+
+// credentialHeaders are request headers that identify the caller. Entries are always keyed by them, as
+// if every response varied on them, so one caller's response is never served to another.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Api-Access-Key", "Ocp-Apim-Subscription-Key", "X-Api-Key"}
+
+// cacheEntry is a stored 200 response along with what is needed to revalidate it
+type cacheEntry struct {
+	uri          string
+	vary         map[string]string
+	body         []byte
+	contentType  string
+	etag         string
+	lastModified string
+	next         string
+	expires      time.Time
+	element      *list.Element
+}
+
+func (e *cacheEntry) fresh() bool {
+	return time.Now().Before(e.expires)
+}
+
+func (e *cacheEntry) matches(request *http.Request) bool {
+	for name, value := range e.vary {
+		if strings.Join(request.Header[http.CanonicalHeaderKey(name)], ", ") != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fill copies a cached response's details into output, leaving the body to be consumed
+func (e *cacheEntry) fill(output *netType.GetOutput, cache string) {
+	output.StatusCode = http.StatusOK
+	output.ContentType = e.contentType
+	output.Next = e.next
+	output.Cache = cache
+}
+
+// responseCache is an LRU of responses, keyed by URI, credentials and the request headers named in Vary
+type responseCache struct {
+	mutex      sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string][]*cacheEntry
+}
+
+func newResponseCache(maxEntries int) *responseCache {
+	return &responseCache{maxEntries: maxEntries, order: list.New(), entries: make(map[string][]*cacheEntry)}
+}
+
+// get returns a copy of the entry matching request, taken under the lock so it can be read while other
+// requests refresh the stored entry
+func (c *responseCache) get(request *http.Request) *cacheEntry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, entry := range c.entries[request.URL.String()] {
+		if entry.matches(request) {
+			c.order.MoveToFront(entry.element)
+			snapshot := *entry
+			return &snapshot
+		}
+	}
+
+	return nil
+}
+
+// store caches a 200 response, unless Cache-Control forbids it or it can be neither reused nor revalidated
+func (c *responseCache) store(request *http.Request, resp *http.Response, body []byte) {
+	if resp.Header.Get("Vary") == "*" || cacheDirectives(resp.Header)["no-store"] != "" {
+		return
+	}
+
+	entry := &cacheEntry{
+		uri:          request.URL.String(),
+		vary:         make(map[string]string),
+		body:         body,
+		contentType:  resp.Header.Get("Content-Type"),
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+		next:         nextLink(resp.Header["Link"], request.URL.String()),
+		expires:      expiry(resp.Header),
+	}
+	if !entry.fresh() && entry.etag == "" && entry.lastModified == "" {
+		return
+	}
+
+	for _, name := range credentialHeaders {
+		entry.vary[name] = strings.Join(request.Header[name], ", ")
+	}
+
+	for _, names := range resp.Header["Vary"] {
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				entry.vary[name] = strings.Join(request.Header[http.CanonicalHeaderKey(name)], ", ")
+			}
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Replace any entry for the same variant
+	entries := c.entries[entry.uri][:0]
+	for _, existing := range c.entries[entry.uri] {
+		if existing.matches(request) {
+			c.order.Remove(existing.element)
+		} else {
+			entries = append(entries, existing)
+		}
+	}
+
+	entry.element = c.order.PushFront(entry)
+	c.entries[entry.uri] = append(entries, entry)
+
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back().Value.(*cacheEntry))
+	}
+}
+
+// refresh updates an entry's lifetime after a 304 Not Modified, both in the copy returned by get and in
+// the stored entry it was taken from
+func (c *responseCache) refresh(entry *cacheEntry, resp *http.Response) {
+	entry.expires = expiry(resp.Header)
+	if etag := resp.Header.Get("ETag"); etag != "" {
+		entry.etag = etag
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); lastModified != "" {
+		entry.lastModified = lastModified
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	stored := entry.element.Value.(*cacheEntry)
+	stored.expires = entry.expires
+	stored.etag = entry.etag
+	stored.lastModified = entry.lastModified
+}
+
+// invalidate removes every entry for uri
+func (c *responseCache) invalidate(uri string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for _, entry := range c.entries[uri] {
+		c.order.Remove(entry.element)
+	}
+	delete(c.entries, uri)
+}
+
+func (c *responseCache) remove(entry *cacheEntry) {
+	c.order.Remove(entry.element)
+
+	entries := c.entries[entry.uri][:0]
+	for _, existing := range c.entries[entry.uri] {
+		if existing != entry {
+			entries = append(entries, existing)
+		}
+	}
+
+	if len(entries) == 0 {
+		delete(c.entries, entry.uri)
+	} else {
+		c.entries[entry.uri] = entries
+	}
+}
+
+// expiry works out when a response stops being fresh from Cache-Control max-age, then Expires
+func expiry(header http.Header) time.Time {
+	directives := cacheDirectives(header)
+	if directives["no-cache"] != "" {
+		return time.Time{}
+	}
+
+	if maxAge, ok := directives["max-age"]; ok {
+		seconds, err := strconv.Atoi(maxAge)
+		if err != nil || seconds <= 0 {
+			return time.Time{}
+		}
+
+		return time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+
+	if expires, err := http.ParseTime(header.Get("Expires")); err == nil {
+		return expires
+	}
+
+	return time.Time{}
+}
+
+// cacheDirectives parses Cache-Control, giving directives without a value a value of "true"
+func cacheDirectives(header http.Header) map[string]string {
+	directives := make(map[string]string)
+
+	for _, values := range header["Cache-Control"] {
+		for _, directive := range strings.Split(values, ",") {
+			parts := strings.SplitN(strings.TrimSpace(directive), "=", 2)
+			if parts[0] == "" {
+				continue
+			}
+
+			value := "true"
+			if len(parts) == 2 {
+				value = strings.Trim(parts[1], `"`)
+			}
+
+			directives[strings.ToLower(parts[0])] = value
+		}
+	}
+
+	return directives
+}
@@ -0,0 +1,171 @@
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	stdnet "net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// ClientConfig describes the shared HTTP client, zero values keep Go's defaults
+type ClientConfig struct {
+	// ConnectTimeout bounds dialing and the TLS handshake, in milliseconds
+	ConnectTimeout int64 `json:"connectTimeout"`
+	// ReadTimeout bounds the wait for response headers once a request is sent, in milliseconds
+	ReadTimeout int64 `json:"readTimeout"`
+	// Timeout bounds the whole request, including reading the body, in milliseconds
+	Timeout int64 `json:"timeout"`
+
+	ProxyURL     string `json:"proxyUrl"`
+	CAFile       string `json:"caFile"`
+	CertFile     string `json:"certFile"`
+	KeyFile      string `json:"keyFile"`
+	MaxIdleConns int    `json:"maxIdleConns"`
+
+	// CacheEntries enables a response cache holding up to this many responses
+	CacheEntries int `json:"cacheEntries"`
+}
+
+var (
+	clientMutex sync.RWMutex
+	client      = &http.Client{}
+	cache       *responseCache
+)
+
+// Configure replaces the client and cache shared by every request, leaving the current ones in place on error
+func Configure(config *ClientConfig) error {
+	newClient, err := NewClient(config)
+	if err != nil {
+		return err
+	}
+
+	clientMutex.Lock()
+	defer clientMutex.Unlock()
+
+	client = newClient
+
+	cache = nil
+	if config.CacheEntries > 0 {
+		cache = newResponseCache(config.CacheEntries)
+	}
+
+	return nil
+}
+
+// Client returns the client shared by every request
+func Client() *http.Client {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+
+	return client
+}
+
+// sharedCache returns the response cache, or nil when caching is disabled
+func sharedCache() *responseCache {
+	clientMutex.RLock()
+	defer clientMutex.RUnlock()
+
+	return cache
+}
+
+// NewClient builds a client from config, only replacing the default transport when a transport setting is given
+func NewClient(config *ClientConfig) (*http.Client, error) {
+	newClient := &http.Client{Timeout: milliseconds(config.Timeout)}
+
+	if config.ConnectTimeout == 0 && config.ReadTimeout == 0 && config.ProxyURL == "" && config.CAFile == "" &&
+		config.CertFile == "" && config.KeyFile == "" && config.MaxIdleConns == 0 {
+		return newClient, nil
+	}
+
+	// Mirror http.DefaultTransport before applying our settings
+	dialer := &stdnet.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if config.ConnectTimeout != 0 {
+		dialer.Timeout = milliseconds(config.ConnectTimeout)
+		transport.TLSHandshakeTimeout = milliseconds(config.ConnectTimeout)
+	}
+
+	if config.ReadTimeout != 0 {
+		transport.ResponseHeaderTimeout = milliseconds(config.ReadTimeout)
+	}
+
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing proxy URL %v: %v", config.ProxyURL, err)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if config.MaxIdleConns != 0 {
+		transport.MaxIdleConns = config.MaxIdleConns
+		transport.MaxIdleConnsPerHost = config.MaxIdleConns
+	}
+
+	tlsConfig, err := newTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	newClient.Transport = transport
+
+	return newClient, nil
+}
+
+func newTLSConfig(config *ClientConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if config.CAFile != "" {
+		pem, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error reading CA file %v: %v", config.CAFile, err)
+		}
+
+		// Trust the system roots as well as the bundle where we can
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Error reading CA file %v: no certificates found", config.CAFile)
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		if config.CertFile == "" || config.KeyFile == "" {
+			return nil, errors.New("Both a certificate and a key file are needed for client certificates")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("Error loading client certificate %v: %v", config.CertFile, err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+func milliseconds(value int64) time.Duration {
+	return time.Duration(value) * time.Millisecond
+}
@@ -0,0 +1,90 @@
+package net
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"io"
+	"strings"
+)
+
+// AcceptEncoding is sent with every request that does not set its own Accept-Encoding header
+const AcceptEncoding = "gzip, deflate, br"
+
+// UnsupportedEncodingError is returned when a response uses a Content-Encoding we cannot decode
+type UnsupportedEncodingError struct {
+	Encoding string
+}
+
+func (e *UnsupportedEncodingError) Error() string {
+	return fmt.Sprintf("Unsupported content encoding %v", e.Encoding)
+}
+
+// decode undoes each encoding listed in a Content-Encoding header, last applied first
+func decode(body io.Reader, contentEncoding string) (io.Reader, error) {
+	encodings := strings.Split(contentEncoding, ",")
+
+	// An empty body is empty whatever encoding it claims, such as the body of an error sent with a gzip header
+	if contentEncoding != "" {
+		buffered := bufio.NewReader(body)
+		if _, err := buffered.Peek(1); err == io.EOF {
+			return buffered, nil
+		}
+		body = buffered
+	}
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		var err error
+
+		switch encoding := strings.ToLower(strings.TrimSpace(encodings[i])); encoding {
+		case "", "identity":
+		case "gzip", "x-gzip":
+			body, err = gzip.NewReader(body)
+		case "deflate":
+			body, err = inflate(body)
+		case "br":
+			body = brotli.NewReader(body)
+		default:
+			return nil, &UnsupportedEncodingError{Encoding: encoding}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return body, nil
+}
+
+// inflate reads deflate bodies, which should be zlib wrapped but are often sent raw
+func inflate(body io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(body)
+
+	header, err := buffered.Peek(2)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// A zlib header uses the deflate method and is a multiple of 31
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,76 @@
+package net
+
+import (
+	"context"
+	"io"
+	stdnet "net"
+	"net/url"
+	"os"
+	"syscall"
+)
+
+// Kinds of failure recorded in GetOutput.ErrorKind
+const (
+	NetworkError    = "network"
+	TimeoutError    = "timeout"
+	HTTPStatusError = "http_status"
+	LimitError      = "limit"
+	CanceledError   = "canceled"
+)
+
+// kindOf tells timeouts and cancellation apart from other failures to connect or read
+func kindOf(ctx context.Context, err error) string {
+	switch ctx.Err() {
+	case context.Canceled:
+		return CanceledError
+	case context.DeadlineExceeded:
+		return TimeoutError
+	}
+
+	if netErr, ok := err.(stdnet.Error); ok && netErr.Timeout() {
+		return TimeoutError
+	}
+
+	return NetworkError
+}
+
+// transient reports whether a failure to connect or read might not happen again, such as a timeout or a
+// refused or reset connection. Failures such as a malformed URI, an unsupported scheme or an untrusted
+// certificate are permanent, as is anything once ctx is done.
+func transient(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	for err != nil {
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+			continue
+		case *stdnet.OpError:
+			if e.Timeout() || e.Temporary() {
+				return true
+			}
+			err = e.Err
+			continue
+		case *os.SyscallError:
+			err = e.Err
+			continue
+		case *stdnet.DNSError:
+			return e.IsTimeout || e.IsTemporary
+		case syscall.Errno:
+			return e == syscall.ECONNREFUSED || e == syscall.ECONNRESET || e == syscall.ECONNABORTED || e == syscall.EPIPE || e.Temporary()
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return true
+		}
+		if netErr, ok := err.(stdnet.Error); ok {
+			return netErr.Timeout() || netErr.Temporary()
+		}
+
+		return false
+	}
+
+	return false
+}
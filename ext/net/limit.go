@@ -0,0 +1,53 @@
+package net
+
+import (
+	"fmt"
+	"io"
+)
+
+// BodyTooLarge is the error code reported when a body is larger than GetInput.MaxBodyBytes
+const BodyTooLarge = "body_too_large"
+
+// BodyTooLargeError is returned when a response body is larger than GetInput.MaxBodyBytes
+type BodyTooLargeError struct {
+	Uri string
+	Max int64
+}
+
+func (e *BodyTooLargeError) Error() string {
+	return fmt.Sprintf("Body from %v is larger than the limit of %v bytes", e.Uri, e.Max)
+}
+
+// limitReader fails with a BodyTooLargeError as soon as more than max bytes are read
+type limitReader struct {
+	reader io.Reader
+	err    *BodyTooLargeError
+	remain int64
+}
+
+func newLimitReader(reader io.Reader, uri string, max int64) io.Reader {
+	if max <= 0 {
+		return reader
+	}
+
+	return &limitReader{reader: reader, err: &BodyTooLargeError{Uri: uri, Max: max}, remain: max}
+}
+
+func (r *limitReader) Read(p []byte) (int, error) {
+	if r.remain < 0 {
+		return 0, r.err
+	}
+
+	// Read one byte past the limit so a body of exactly max bytes is allowed
+	if int64(len(p)) > r.remain+1 {
+		p = p[:r.remain+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remain -= int64(n)
+	if r.remain < 0 {
+		return n + int(r.remain), r.err
+	}
+
+	return n, err
+}
@@ -0,0 +1,88 @@
+package net
+
+import (
+	"net/url"
+	"strings"
+)
+
+// nextLink returns the target of the first link with rel="next" in Link headers, resolved against uri
+func nextLink(headers []string, uri string) string {
+	for _, header := range headers {
+		for _, link := range splitLinks(header) {
+			target, params := parseLink(link)
+			if target == "" {
+				continue
+			}
+
+			for _, rel := range strings.Fields(params["rel"]) {
+				if strings.EqualFold(rel, "next") {
+					return resolve(uri, target)
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// splitLinks splits a Link header into its links, ignoring commas inside a target or a quoted parameter
+func splitLinks(header string) []string {
+	var links []string
+	inTarget, inQuotes, start := false, false, 0
+
+	for i, r := range header {
+		switch {
+		case r == '<' && !inQuotes:
+			inTarget = true
+		case r == '>' && !inQuotes:
+			inTarget = false
+		case r == '"' && !inTarget:
+			inQuotes = !inQuotes
+		case r == ',' && !inTarget && !inQuotes:
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(links, header[start:])
+}
+
+// parseLink splits a link into its target and parameters, with parameter names lower cased
+func parseLink(link string) (string, map[string]string) {
+	link = strings.TrimSpace(link)
+	if !strings.HasPrefix(link, "<") {
+		return "", nil
+	}
+
+	end := strings.Index(link, ">")
+	if end < 0 {
+		return "", nil
+	}
+
+	params := make(map[string]string)
+	for _, param := range strings.Split(link[end+1:], ";") {
+		parts := strings.SplitN(param, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		params[strings.ToLower(strings.TrimSpace(parts[0]))] = strings.Trim(strings.TrimSpace(parts[1]), `"`)
+	}
+
+	return strings.TrimSpace(link[1:end]), params
+}
+
+// resolve resolves reference against base, returning reference unchanged if either cannot be parsed
+func resolve(base string, reference string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return reference
+	}
+
+	referenceURL, err := url.Parse(reference)
+	if err != nil {
+		return reference
+	}
+
+	return baseURL.ResolveReference(referenceURL).String()
+}
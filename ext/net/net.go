@@ -1,38 +1,182 @@
 package net
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 )
 
+// Get fetches input.Uri, or posts input.Sparql to it, reading the whole body into output.Body
 func Get(input *netType.GetInput) (*netType.GetOutput, error) {
+	return GetContext(context.Background(), input)
+}
+
+// GetContext is Get, abandoning the request and any retries once ctx is done
+func GetContext(ctx context.Context, input *netType.GetInput) (*netType.GetOutput, error) {
+	return StreamContext(ctx, input, nil)
+}
+
+// Stream fetches input.Uri, handing the body of a successful response to consume as it arrives rather
+// than buffering it. Output.Body is left empty, and failures once consume has started are not retried.
+func Stream(input *netType.GetInput, consume func(output *netType.GetOutput, body io.Reader) error) (*netType.GetOutput, error) {
+	return StreamContext(context.Background(), input, consume)
+}
+
+// StreamContext is Stream, abandoning the request and any retries once ctx is done
+func StreamContext(ctx context.Context, input *netType.GetInput, consume func(output *netType.GetOutput, body io.Reader) error) (*netType.GetOutput, error) {
+	request, err := getRequest(input)
+	if err != nil {
+		return &netType.GetOutput{Uri: input.Uri, Error: err.Error(), ErrorKind: NetworkError}, err
+	}
+
+	return DoStreamContext(ctx, request, consume)
+}
+
+// Do sends request, reading the whole body into output.Body
+func Do(request *netType.Request) (*netType.GetOutput, error) {
+	return DoContext(context.Background(), request)
+}
+
+// DoContext is Do, abandoning the request and any retries once ctx is done
+func DoContext(ctx context.Context, request *netType.Request) (*netType.GetOutput, error) {
+	return DoStreamContext(ctx, request, nil)
+}
+
+// DoStream sends request, handing the body of a successful response to consume as Stream does.
+// A nil consume reads the whole body into output.Body as Do does.
+func DoStream(request *netType.Request, consume func(output *netType.GetOutput, body io.Reader) error) (*netType.GetOutput, error) {
+	return DoStreamContext(context.Background(), request, consume)
+}
+
+// DoStreamContext is DoStream, abandoning the request and any retries once ctx is done
+func DoStreamContext(ctx context.Context, request *netType.Request, consume func(output *netType.GetOutput, body io.Reader) error) (*netType.GetOutput, error) {
+	if consume == nil {
+		return fetch(ctx, request, true, readBody)
+	}
+
+	return fetch(ctx, request, false, consume)
+}
+
+// readBody buffers a whole body into output.Body
+func readBody(output *netType.GetOutput, body io.Reader) error {
+	data, err := ioutil.ReadAll(body)
+	if err == nil {
+		output.Body = data
+	}
+
+	return err
+}
+
+func fetch(ctx context.Context, input *netType.Request, restartable bool, consume func(*netType.GetOutput, io.Reader) error) (*netType.GetOutput, error) {
 	output := &netType.GetOutput{Uri: input.Uri}
+	policy := newRetryPolicy(input.Retry)
 
-	// Build a new get request object
-	request, err := http.NewRequest("GET", input.Uri, nil)
+	for attempt := 1; ; attempt++ {
+		retryable, retryAfter, err := get(ctx, input, output, policy, restartable, consume)
+		output.Attempts = int32(attempt)
+
+		if err == nil {
+			return output, nil
+		}
+
+		output.Retryable = retryable
+
+		output.AttemptErrors = append(output.AttemptErrors, output.Error)
+		if !retryable || attempt >= policy.maxAttempts {
+			return output, err
+		}
+
+		select {
+		case <-ctx.Done():
+			output.Error = ctx.Err().Error()
+			output.ErrorKind = kindOf(ctx, ctx.Err())
+			output.Retryable = false
+			return output, ctx.Err()
+		case <-time.After(policy.delay(attempt, retryAfter)):
+		}
+	}
+}
+
+// get makes a single attempt, reporting whether a failure is worth retrying and any Retry-After delay
+func get(ctx context.Context, input *netType.Request, output *netType.GetOutput, policy *retryPolicy, restartable bool, consume func(*netType.GetOutput, io.Reader) error) (bool, time.Duration, error) {
+	// Clear anything left over from a previous attempt
+	output.Body = nil
+	output.StatusCode = 0
+	output.ContentType = ""
+	output.Error = ""
+	output.Cache = ""
+	output.ErrorCode = ""
+	output.ErrorKind = ""
+	output.Retryable = false
+	output.CompressedBytes = 0
+	output.DecompressedBytes = 0
+	output.Next = ""
+
+	// Build a new request object, with a fresh reader over any body
+	request, err := newRequest(input)
 	if err != nil {
 		output.Error = err.Error()
-		return output, err
+		output.ErrorKind = NetworkError
+		return false, 0, err
 	}
+	request = request.WithContext(ctx)
 
 	// Add any header objects to our request
 	for i := 0; i < len(input.Headers); i++ {
 		request.Header.Add(input.Headers[i].Key, input.Headers[i].Value)
 	}
 
-	// Perform our request
-	client := http.Client{}
-	resp, err := client.Do(request)
+	// Ask for compressed bodies, which we always decode ourselves as setting the header stops the transport doing so
+	if request.Header.Get("Accept-Encoding") == "" {
+		request.Header.Set("Accept-Encoding", AcceptEncoding)
+	}
+
+	// Serve fresh responses from the cache, and revalidate stale ones, only GETs are cached
+	cache := sharedCache()
+	if request.Method != "GET" {
+		// Other methods may change the resource, so stop serving what is stored for it once they succeed
+		defer invalidate(cache, request, output)
+		cache = nil
+	}
+	var entry *cacheEntry
+	if cache != nil {
+		entry = cache.get(request)
+		if entry != nil && entry.fresh() {
+			entry.fill(output, CacheHit)
+			return consumeBody(ctx, input, output, cachedBody(entry, input), false, consume)
+		}
+
+		if entry != nil && entry.etag != "" {
+			request.Header.Set("If-None-Match", entry.etag)
+		}
+		if entry != nil && entry.lastModified != "" {
+			request.Header.Set("If-Modified-Since", entry.lastModified)
+		}
+	}
+
+	// Perform our request with the shared client, only transient connection failures are retryable
+	resp, err := Client().Do(request)
 	if resp != nil {
 		defer resp.Body.Close()
 	}
 
 	if err != nil {
 		output.Error = err.Error()
-		return output, err
+		output.ErrorKind = kindOf(ctx, err)
+		return transient(ctx, err), 0, err
+	}
+
+	if entry != nil && resp.StatusCode == http.StatusNotModified {
+		cache.refresh(entry, resp)
+		entry.fill(output, CacheRevalidated)
+		return consumeBody(ctx, input, output, cachedBody(entry, input), false, consume)
 	}
 
 	// Store the response code
@@ -40,24 +184,195 @@ func Get(input *netType.GetInput) (*netType.GetOutput, error) {
 		output.StatusCode = int32(resp.StatusCode)
 	}
 
-	// Read the body into a []byte
-	body, err := ioutil.ReadAll(resp.Body)
+	// Store the content type so the processor can pick a decoder, and any link to the next page
+	output.ContentType = resp.Header.Get("Content-Type")
+	output.Next = nextLink(resp.Header["Link"], input.Uri)
 
-	if err != nil {
-		errorMessage := fmt.Sprintf("Error reading body from %v: %v", input.Uri, err)
-		output.Error = errorMessage
-		return output, err
+	if cache != nil {
+		output.Cache = CacheMiss
+	}
+
+	// Give up before reading anything when the body is declared to be too large
+	contentEncoding := resp.Header.Get("Content-Encoding")
+	if input.MaxBodyBytes > 0 && contentEncoding == "" && resp.ContentLength > input.MaxBodyBytes {
+		return false, 0, tooLarge(output, &BodyTooLargeError{Uri: input.Uri, Max: input.MaxBodyBytes})
+	}
+
+	// Count the body as received and once decoded, the limit applies to the decoded body
+	compressed := &countingReader{reader: resp.Body}
+	decompressed := &countingReader{reader: compressed}
+	defer func() {
+		output.CompressedBytes = compressed.count
+		output.DecompressedBytes = decompressed.count
+	}()
+
+	// Check the status before decoding, so a body that cannot be decoded does not hide a status worth retrying
+	acceptable := accepted(input.AcceptedStatusCodes, request.Method, resp.StatusCode)
+
+	if !empty(resp.StatusCode) {
+		decoded, err := decode(compressed, contentEncoding)
+		switch {
+		case err == nil:
+			decompressed.reader = decoded
+		case acceptable:
+			errorMessage := fmt.Sprintf("Error decoding body from %v: %v", input.Uri, err)
+			output.Error = errorMessage
+			output.ErrorKind = kindOf(ctx, err)
+			return false, 0, err
+		default:
+			// Report the status without the body
+			decompressed.reader = bytes.NewReader(nil)
+		}
 	}
+	limited := newLimitReader(decompressed, input.Uri, input.MaxBodyBytes)
 
-	output.Body = body
+	// Handle responses outside the accepted status codes, reading the body into our error
+	if !acceptable {
+		body, err := ioutil.ReadAll(limited)
+		if tooLargeErr, ok := err.(*BodyTooLargeError); ok {
+			return false, 0, tooLarge(output, tooLargeErr)
+		}
+		if err != nil {
+			errorMessage := fmt.Sprintf("Error reading body from %v: %v", input.Uri, err)
+			output.Error = errorMessage
+			output.ErrorKind = kindOf(ctx, err)
+			return ctx.Err() == nil, 0, err
+		}
+
+		output.Body = body
 
-	// Handle non-200 responses
-	if resp.StatusCode != 200 {
 		errorMessage := fmt.Sprintf("Received %v status code from %v: %s", resp.StatusCode, input.Uri, body)
 
 		output.Error = errorMessage
-		return output, errors.New(errorMessage)
+		output.ErrorKind = HTTPStatusError
+		return policy.statusCodes[resp.StatusCode], parseRetryAfter(resp.Header.Get("Retry-After")), errors.New(errorMessage)
+	}
+
+	// Responses without content are consumed as empty bodies
+	if empty(resp.StatusCode) {
+		return consumeBody(ctx, input, output, &bodyReader{reader: bytes.NewReader(nil)}, restartable, consume)
+	}
+
+	// Keep a copy of the body for the cache as it is consumed
+	store := cache != nil && resp.StatusCode == http.StatusOK
+	body := &bodyReader{reader: limited}
+	var copied bytes.Buffer
+	if store {
+		body.reader = io.TeeReader(limited, &copied)
+	}
+
+	retryable, retryAfter, err := consumeBody(ctx, input, output, body, restartable, consume)
+	if err == nil && store {
+		// Only cache the body once all of it has been read
+		if _, err := io.Copy(ioutil.Discard, body); err == nil {
+			cache.store(request, resp, copied.Bytes())
+		}
+	}
+
+	return retryable, retryAfter, err
+}
+
+// cachedBody reads the body of a cached response, which is still held to input's body limit
+func cachedBody(entry *cacheEntry, input *netType.Request) *bodyReader {
+	return &bodyReader{reader: newLimitReader(bytes.NewReader(entry.body), input.Uri, input.MaxBodyBytes)}
+}
+
+// consumeBody passes body to consume, telling errors reading the body apart from errors consuming it
+func consumeBody(ctx context.Context, input *netType.Request, output *netType.GetOutput, body *bodyReader, restartable bool, consume func(*netType.GetOutput, io.Reader) error) (bool, time.Duration, error) {
+	err := consume(output, body)
+	if tooLargeErr, ok := body.err.(*BodyTooLargeError); ok {
+		return false, 0, tooLarge(output, tooLargeErr)
+	}
+	if body.err != nil {
+		errorMessage := fmt.Sprintf("Error reading body from %v: %v", input.Uri, body.err)
+		output.Error = errorMessage
+		output.ErrorKind = kindOf(ctx, body.err)
+		return restartable && ctx.Err() == nil, 0, body.err
+	}
+
+	if err != nil {
+		output.Error = err.Error()
+		if ctx.Err() != nil {
+			output.ErrorKind = kindOf(ctx, err)
+		}
+		return false, 0, err
+	}
+
+	return false, 0, nil
+}
+
+// tooLarge records a BodyTooLargeError in output
+func tooLarge(output *netType.GetOutput, err *BodyTooLargeError) error {
+	output.Body = nil
+	output.Error = err.Error()
+	output.ErrorCode = BodyTooLarge
+	output.ErrorKind = LimitError
+
+	return err
+}
+
+// bodyReader remembers the first error, other than EOF, returned by the reader it wraps,
+// and keeps returning EOF once it has been reached
+type bodyReader struct {
+	reader io.Reader
+	err    error
+	eof    bool
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	if r.eof {
+		return 0, io.EOF
+	}
+
+	n, err := r.reader.Read(p)
+	if err == io.EOF {
+		r.eof = true
+	} else if err != nil && r.err == nil {
+		r.err = err
+	}
+
+	return n, err
+}
+
+// newRequest builds the request for a single attempt, a GET when input does not set a method
+func newRequest(input *netType.Request) (*http.Request, error) {
+	method := strings.ToUpper(input.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	var body io.Reader
+	if len(input.Body) > 0 {
+		body = bytes.NewReader(input.Body)
+	}
+
+	request, err := http.NewRequest(method, input.Uri, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.ContentType != "" {
+		request.Header.Set("Content-Type", input.ContentType)
+	}
+
+	return request, nil
+}
+
+// invalidate removes what cache holds for request's URI once a request that may have changed it succeeds
+func invalidate(cache *responseCache, request *http.Request, output *netType.GetOutput) {
+	if cache == nil || safe(request.Method) || output.StatusCode < 200 || output.StatusCode >= 400 {
+		return
+	}
+
+	cache.invalidate(request.URL.String())
+}
+
+// safe reports whether method is one that does not change the resource
+func safe(method string) bool {
+	switch method {
+	case "GET", "HEAD", "OPTIONS", "TRACE":
+		return true
 	}
 
-	return output, err
+	return false
 }
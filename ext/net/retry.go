@@ -0,0 +1,102 @@
+package net
+
+import (
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultBackoffBase is the delay before the first retry when a policy does not set one
+	DefaultBackoffBase = 100 * time.Millisecond
+	// DefaultBackoffCap is the longest delay between attempts when a policy does not set one
+	DefaultBackoffCap = 10 * time.Second
+)
+
+// DefaultRetryableStatusCodes are retried when a policy does not list its own
+var DefaultRetryableStatusCodes = []int32{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+type retryPolicy struct {
+	maxAttempts       int
+	base              time.Duration
+	cap               time.Duration
+	jitter            float64
+	statusCodes       map[int]bool
+	respectRetryAfter bool
+}
+
+// newRetryPolicy fills in the defaults for input, a nil input means a single attempt. The default status
+// codes still apply then, so a failure reports whether another attempt might succeed.
+func newRetryPolicy(input *netType.GetInput_RetryPolicy) *retryPolicy {
+	policy := &retryPolicy{maxAttempts: 1, base: DefaultBackoffBase, cap: DefaultBackoffCap, statusCodes: map[int]bool{}}
+	if input == nil {
+		input = &netType.GetInput_RetryPolicy{}
+	}
+
+	if input.MaxAttempts > 1 {
+		policy.maxAttempts = int(input.MaxAttempts)
+	}
+	if input.BackoffBase > 0 {
+		policy.base = milliseconds(input.BackoffBase)
+	}
+	if input.BackoffCap > 0 {
+		policy.cap = milliseconds(input.BackoffCap)
+	}
+	if input.Jitter > 0 && input.Jitter <= 1 {
+		policy.jitter = input.Jitter
+	}
+	policy.respectRetryAfter = input.RespectRetryAfter
+
+	statusCodes := input.RetryableStatusCodes
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultRetryableStatusCodes
+	}
+	for _, statusCode := range statusCodes {
+		policy.statusCodes[int(statusCode)] = true
+	}
+
+	return policy
+}
+
+// delay is how long to wait after the given (1-based) attempt, honouring Retry-After up to the cap
+func (p *retryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.base
+	for i := 1; i < attempt && delay < p.cap; i++ {
+		delay *= 2
+	}
+	if delay > p.cap {
+		delay = p.cap
+	}
+
+	if p.jitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.jitter * float64(delay))
+	}
+
+	if p.respectRetryAfter && retryAfter > delay {
+		delay = retryAfter
+		if delay > p.cap {
+			delay = p.cap
+		}
+	}
+
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
@@ -0,0 +1,74 @@
+package net
+
+import (
+	"fmt"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Encodings for a SPARQL query sent in the body of a POST
+const (
+	FormEncoding   = "form"
+	DirectEncoding = "direct"
+)
+
+// Accept headers sent with SPARQL queries that do not set their own, preferring the syntaxes quickest to decode
+const (
+	// SparqlGraphAccept is sent with CONSTRUCT and DESCRIBE queries
+	SparqlGraphAccept = "application/n-triples, text/turtle;q=0.9, application/ld+json;q=0.8, application/rdf+xml;q=0.7"
+	// SparqlResultsAccept is sent with SELECT and ASK queries
+	SparqlResultsAccept = "application/sparql-results+json, application/sparql-results+xml;q=0.9"
+)
+
+// queryForm matches the keyword starting a query, after any comments and PREFIX or BASE declarations
+var queryForm = regexp.MustCompile(`(?i)^(?:\s+|#[^\n]*|PREFIX\s+[^\s:]*:\s*<[^>]*>|BASE\s*<[^>]*>)*(SELECT|ASK|CONSTRUCT|DESCRIBE)\b`)
+
+// sparqlAccept picks the Accept header for a query by its form, asking for a graph when the form is not found
+func sparqlAccept(query string) string {
+	match := queryForm.FindStringSubmatch(query)
+	if match != nil && (strings.EqualFold(match[1], "SELECT") || strings.EqualFold(match[1], "ASK")) {
+		return SparqlResultsAccept
+	}
+
+	return SparqlGraphAccept
+}
+
+// getRequest turns input into a Request, a POST when input holds a SPARQL query and a GET otherwise
+func getRequest(input *netType.GetInput) (*netType.Request, error) {
+	request := &netType.Request{
+		Method:              "GET",
+		Uri:                 input.Uri,
+		Headers:             input.Headers,
+		Retry:               input.Retry,
+		MaxBodyBytes:        input.MaxBodyBytes,
+		AcceptedStatusCodes: input.AcceptedStatusCodes,
+	}
+
+	sparql := input.Sparql
+	if sparql == nil {
+		return request, nil
+	}
+
+	switch sparql.Encoding {
+	case "", FormEncoding:
+		request.Body = []byte(url.Values{"query": {sparql.Query}}.Encode())
+		request.ContentType = "application/x-www-form-urlencoded"
+	case DirectEncoding:
+		request.Body = []byte(sparql.Query)
+		request.ContentType = "application/sparql-query"
+	default:
+		return nil, fmt.Errorf("Unknown SPARQL query encoding %v", sparql.Encoding)
+	}
+	request.Method = "POST"
+
+	for _, header := range input.Headers {
+		if strings.EqualFold(header.Key, "Accept") {
+			return request, nil
+		}
+	}
+	request.Headers = append([]*netType.GetInput_Header{{Key: "Accept", Value: sparqlAccept(sparql.Query)}}, input.Headers...)
+
+	return request, nil
+}
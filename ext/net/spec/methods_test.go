@@ -1,13 +1,28 @@
 package spec
 
 import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/x509"
 	"errors"
+	"github.com/andybalholm/brotli"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/ukparliament/gromnative/ext/net"
 	. "github.com/ukparliament/gromnative/ext/types/net"
 	"gopkg.in/jarcoal/httpmock.v1"
+	"io"
+	"io/ioutil"
+	stdnet "net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"syscall"
+	"time"
 )
 
 type errReader int
@@ -26,10 +41,15 @@ var _ = Describe("Net", func() {
 			It("makes the expected request", func() {
 				resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
+				body := []byte("<https://id.parliament.uk/43RHonMf> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> \"Diane\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personOtherNames> \"Julie\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personFamilyName> \"Abbott\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/oppositionPersonHasOppositionIncumbency> <https://id.parliament.uk/wE8Hq016> .\\n\\r<https://id.parliament.uk/wE8Hq016> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/OppositionIncumbency> .\\n\\r<https://id.parliament.uk/wE8Hq016> <https://id.parliament.uk/schema/incumbencyStartDate> \"2016-06-27+01:00\"^^<http://www.w3.org/2001/XMLSchema#date> .")
+
 				Expect(resp).To(Equal(&GetOutput{
-					Uri:        "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
-					Body:       []byte("<https://id.parliament.uk/43RHonMf> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> \"Diane\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personOtherNames> \"Julie\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personFamilyName> \"Abbott\" .\\n\\r<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/oppositionPersonHasOppositionIncumbency> <https://id.parliament.uk/wE8Hq016> .\\n\\r<https://id.parliament.uk/wE8Hq016> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/OppositionIncumbency> .\\n\\r<https://id.parliament.uk/wE8Hq016> <https://id.parliament.uk/schema/incumbencyStartDate> \"2016-06-27+01:00\"^^<http://www.w3.org/2001/XMLSchema#date> ."),
-					StatusCode: 200,
+					Uri:               "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+					Body:              body,
+					StatusCode:        200,
+					Attempts:          1,
+					CompressedBytes:   int64(len(body)),
+					DecompressedBytes: int64(len(body)),
 				}))
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -43,6 +63,7 @@ var _ = Describe("Net", func() {
 							httpHeaders := http.Header{}
 							httpHeaders.Add("Foo", "Bar")
 							httpHeaders.Add("Bar", "Baz")
+							httpHeaders.Add("Accept-Encoding", net.AcceptEncoding)
 
 							Expect(req.Header).To(Equal(httpHeaders))
 
@@ -55,14 +76,62 @@ var _ = Describe("Net", func() {
 					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf", Headers: headers})
 
 					Expect(resp).To(Equal(&GetOutput{
-						Uri:        "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
-						Body:       []byte("done"),
-						StatusCode: 200,
+						Uri:               "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+						Body:              []byte("done"),
+						StatusCode:        200,
+						Attempts:          1,
+						CompressedBytes:   4,
+						DecompressedBytes: 4,
 					}))
 					Expect(err).NotTo(HaveOccurred())
 				})
 			})
 
+			Context("with a content type", func() {
+				BeforeEach(func() {
+					httpmock.RegisterResponder(
+						"GET",
+						"https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+						func(req *http.Request) (*http.Response, error) {
+							resp := httpmock.NewStringResponse(200, "done")
+							resp.Header.Set("Content-Type", "text/turtle; charset=utf-8")
+
+							return resp, nil
+						},
+					)
+				})
+
+				It("stores the content type", func() {
+					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
+
+					Expect(resp.ContentType).To(Equal("text/turtle; charset=utf-8"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("with a Link header", func() {
+				BeforeEach(func() {
+					httpmock.RegisterResponder(
+						"GET",
+						"https://api.parliament.uk/query/person_index?page=1",
+						func(req *http.Request) (*http.Response, error) {
+							resp := httpmock.NewStringResponse(200, "done")
+							resp.Header.Add("Link", `<https://api.parliament.uk/query/person_index?page=0>; rel="prev first"`)
+							resp.Header.Add("Link", `<https://api.parliament.uk/query/person_index?ids=1,2>; title="a, b"; rel="alternate", <?page=2>; rel="next"`)
+
+							return resp, nil
+						},
+					)
+				})
+
+				It("stores the next page resolved against the URI", func() {
+					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_index?page=1"})
+
+					Expect(resp.Next).To(Equal("https://api.parliament.uk/query/person_index?page=2"))
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
 			Context("with an error making the request", func() {
 				BeforeEach(func() {
 					httpmock.RegisterResponder(
@@ -78,8 +147,12 @@ var _ = Describe("Net", func() {
 					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
 					Expect(resp).To(Equal(&GetOutput{
-						Uri:   "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
-						Error: "Get https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: There was a problem",
+						Uri:           "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+						Error:         "Get https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: There was a problem",
+						Attempts:      1,
+						AttemptErrors: []string{"Get https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: There was a problem"},
+						ErrorKind:     net.NetworkError,
+						Retryable:     false,
 					}))
 
 					Expect(err).To(HaveOccurred())
@@ -87,6 +160,50 @@ var _ = Describe("Net", func() {
 				})
 			})
 
+			Context("with a transient error making the request", func() {
+				uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+
+				respondWith := func(err error) {
+					httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+						return nil, err
+					})
+				}
+
+				It("reports a refused connection as retryable", func() {
+					respondWith(&stdnet.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: syscall.ECONNREFUSED}})
+
+					resp, err := net.Get(&GetInput{Uri: uri})
+
+					Expect(err).To(HaveOccurred())
+					Expect(resp.ErrorKind).To(Equal(net.NetworkError))
+					Expect(resp.Retryable).To(BeTrue())
+				})
+
+				It("reports a reset connection as retryable", func() {
+					respondWith(&stdnet.OpError{Op: "read", Net: "tcp", Err: &os.SyscallError{Syscall: "read", Err: syscall.ECONNRESET}})
+
+					resp, _ := net.Get(&GetInput{Uri: uri})
+
+					Expect(resp.Retryable).To(BeTrue())
+				})
+
+				It("reports an untrusted certificate as permanent", func() {
+					respondWith(x509.UnknownAuthorityError{})
+
+					resp, _ := net.Get(&GetInput{Uri: uri})
+
+					Expect(resp.Retryable).To(BeFalse())
+				})
+
+				It("reports a missing host as permanent", func() {
+					respondWith(&stdnet.OpError{Op: "dial", Net: "tcp", Err: &stdnet.DNSError{Err: "no such host", Name: "api.parliament.uk"}})
+
+					resp, _ := net.Get(&GetInput{Uri: uri})
+
+					Expect(resp.Retryable).To(BeFalse())
+				})
+			})
+
 			Context("with an error reading the response body", func() {
 				BeforeEach(func() {
 					httpmock.RegisterResponder(
@@ -102,9 +219,13 @@ var _ = Describe("Net", func() {
 					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
 					Expect(resp).To(Equal(&GetOutput{
-						Uri:        "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
-						StatusCode: int32(200),
-						Error:      "Error reading body from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: test error",
+						Uri:           "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+						StatusCode:    int32(200),
+						Error:         "Error reading body from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: test error",
+						Attempts:      1,
+						AttemptErrors: []string{"Error reading body from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: test error"},
+						ErrorKind:     net.NetworkError,
+						Retryable:     true,
 					}))
 
 					Expect(err).To(HaveOccurred())
@@ -127,10 +248,15 @@ var _ = Describe("Net", func() {
 					resp, err := net.Get(&GetInput{Uri: "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"})
 
 					Expect(resp).To(Equal(&GetOutput{
-						Uri:        "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
-						Body:       []byte("Error"),
-						StatusCode: int32(500),
-						Error:      "Received 500 status code from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: Error",
+						Uri:               "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf",
+						Body:              []byte("Error"),
+						StatusCode:        int32(500),
+						Error:             "Received 500 status code from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: Error",
+						Attempts:          1,
+						AttemptErrors:     []string{"Received 500 status code from https://api.parliament.uk/query/person_by_id?person_id=43RHonMf: Error"},
+						ErrorKind:         net.HTTPStatusError,
+						CompressedBytes:   5,
+						DecompressedBytes: 5,
 					}))
 
 					Expect(err).To(HaveOccurred())
@@ -139,6 +265,580 @@ var _ = Describe("Net", func() {
 			})
 		})
 
+		Context("with a retry policy", func() {
+			uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+			var statusCodes []int
+
+			BeforeEach(func() {
+				statusCodes = []int{503, 502, 200}
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					statusCode := statusCodes[0]
+					statusCodes = statusCodes[1:]
+
+					return httpmock.NewStringResponse(statusCode, http.StatusText(statusCode)), nil
+				})
+			})
+
+			It("retries retryable status codes", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 1}})
+
+				Expect(resp.StatusCode).To(Equal(int32(200)))
+				Expect(resp.Attempts).To(Equal(int32(3)))
+				Expect(resp.AttemptErrors).To(Equal([]string{
+					"Received 503 status code from " + uri + ": Service Unavailable",
+					"Received 502 status code from " + uri + ": Bad Gateway",
+				}))
+				Expect(resp.Error).To(BeEmpty())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("stops after the maximum number of attempts", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 2, BackoffBase: 1}})
+
+				Expect(resp.StatusCode).To(Equal(int32(502)))
+				Expect(resp.Attempts).To(Equal(int32(2)))
+				Expect(resp.ErrorKind).To(Equal(net.HTTPStatusError))
+				Expect(resp.Retryable).To(BeTrue())
+				Expect(err).To(MatchError("Received 502 status code from " + uri + ": Bad Gateway"))
+			})
+
+			It("reports retryable status codes without a policy", func() {
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(resp.StatusCode).To(Equal(int32(503)))
+				Expect(resp.Retryable).To(BeTrue())
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("does not retry other status codes", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 1, RetryableStatusCodes: []int32{504}}})
+
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("with a response cache", func() {
+			uri := "https://api.parliament.uk/query/house_index"
+			var requests []*http.Request
+			var headers http.Header
+
+			BeforeEach(func() {
+				requests = nil
+				headers = http.Header{}
+				Expect(net.Configure(&net.ClientConfig{CacheEntries: 2})).To(Succeed())
+
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					requests = append(requests, req)
+					if req.Header.Get("If-None-Match") == `"v1"` {
+						return httpmock.NewStringResponse(304, ""), nil
+					}
+
+					resp := httpmock.NewStringResponse(200, "houses")
+					for key, values := range headers {
+						resp.Header[key] = values
+					}
+
+					return resp, nil
+				})
+			})
+
+			AfterEach(func() {
+				Expect(net.Configure(&net.ClientConfig{})).To(Succeed())
+			})
+
+			It("serves fresh responses from the cache", func() {
+				headers.Set("Cache-Control", "public, max-age=60")
+
+				first, _ := net.Get(&GetInput{Uri: uri})
+				second, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(first.Cache).To(Equal(net.CacheMiss))
+				Expect(second.Cache).To(Equal(net.CacheHit))
+				Expect(second.Body).To(Equal([]byte("houses")))
+				Expect(requests).To(HaveLen(1))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("revalidates stale responses with their ETag", func() {
+				headers.Set("ETag", `"v1"`)
+
+				net.Get(&GetInput{Uri: uri})
+				second, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(second.Cache).To(Equal(net.CacheRevalidated))
+				Expect(second.StatusCode).To(Equal(int32(200)))
+				Expect(second.Body).To(Equal([]byte("houses")))
+				Expect(requests).To(HaveLen(2))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("revalidates the same response from several requests at once", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					if req.Header.Get("If-None-Match") == `"v1"` {
+						// Hold each revalidation open so the requests overlap rather than running one after another
+						time.Sleep(10 * time.Millisecond)
+
+						resp := httpmock.NewStringResponse(304, "")
+						resp.Header.Set("ETag", `"v1"`)
+						resp.Header.Set("Last-Modified", "Mon, 01 Jan 2018 00:00:00 GMT")
+						return resp, nil
+					}
+
+					resp := httpmock.NewStringResponse(200, "houses")
+					resp.Header.Set("ETag", `"v1"`)
+					return resp, nil
+				})
+
+				net.Get(&GetInput{Uri: uri})
+
+				var wg sync.WaitGroup
+				outputs := make([]*GetOutput, 20)
+				for i := range outputs {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						outputs[i], _ = net.Get(&GetInput{Uri: uri})
+					}(i)
+				}
+				wg.Wait()
+
+				for _, output := range outputs {
+					Expect(output.Cache).To(Equal(net.CacheRevalidated))
+					Expect(output.Body).To(Equal([]byte("houses")))
+				}
+			})
+
+			It("applies the body limit to cached responses", func() {
+				headers.Set("Cache-Control", "max-age=60")
+
+				net.Get(&GetInput{Uri: uri})
+				second, err := net.Get(&GetInput{Uri: uri, MaxBodyBytes: 3})
+
+				Expect(second.Cache).To(Equal(net.CacheHit))
+				Expect(second.ErrorCode).To(Equal(net.BodyTooLarge))
+				Expect(err).To(Equal(&net.BodyTooLargeError{Uri: uri, Max: 3}))
+			})
+
+			It("keys responses by the headers they vary on", func() {
+				headers.Set("Cache-Control", "max-age=60")
+				headers.Set("Vary", "Accept")
+
+				net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Accept", Value: "text/turtle"}}})
+				second, _ := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Accept", Value: "application/n-triples"}}})
+				third, _ := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Accept", Value: "text/turtle"}}})
+
+				Expect(second.Cache).To(Equal(net.CacheMiss))
+				Expect(third.Cache).To(Equal(net.CacheHit))
+				Expect(requests).To(HaveLen(2))
+			})
+
+			It("keys responses by the credentials they were requested with", func() {
+				Expect(net.Configure(&net.ClientConfig{CacheEntries: 4})).To(Succeed())
+				headers.Set("Cache-Control", "max-age=60")
+
+				net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Authorization", Value: "Bearer one"}}})
+				second, _ := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Authorization", Value: "Bearer two"}}})
+				third, _ := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Api-Access-Key", Value: "12345678"}}})
+				fourth, _ := net.Get(&GetInput{Uri: uri})
+				fifth, _ := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Authorization", Value: "Bearer one"}}})
+
+				Expect(second.Cache).To(Equal(net.CacheMiss))
+				Expect(third.Cache).To(Equal(net.CacheMiss))
+				Expect(fourth.Cache).To(Equal(net.CacheMiss))
+				Expect(fifth.Cache).To(Equal(net.CacheHit))
+				Expect(requests).To(HaveLen(4))
+			})
+
+			It("does not store responses marked no-store", func() {
+				headers.Set("Cache-Control", "no-store, max-age=60")
+
+				net.Get(&GetInput{Uri: uri})
+				second, _ := net.Get(&GetInput{Uri: uri})
+
+				Expect(second.Cache).To(Equal(net.CacheMiss))
+				Expect(requests).To(HaveLen(2))
+			})
+		})
+
+		Context("with accepted status codes", func() {
+			uri := "https://api.parliament.uk/query/person_index"
+
+			It("accepts 203 by default", func() {
+				httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(203, "cached"))
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.StatusCode).To(Equal(int32(203)))
+				Expect(resp.Body).To(Equal([]byte("cached")))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns an empty body for 204", func() {
+				httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(204, ""))
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.StatusCode).To(Equal(int32(204)))
+				Expect(resp.Body).To(BeEmpty())
+				Expect(resp.Error).To(BeEmpty())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("uses the status codes given in place of the defaults", func() {
+				httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(203, "cached"))
+
+				resp, err := net.Get(&GetInput{Uri: uri, AcceptedStatusCodes: []int32{200}})
+
+				Expect(resp.ErrorKind).To(Equal(net.HTTPStatusError))
+				Expect(err).To(MatchError("Received 203 status code from " + uri + ": cached"))
+			})
+		})
+
+		Context("with a compressed body", func() {
+			uri := "https://api.parliament.uk/query/person_index"
+			body := bytes.Repeat([]byte("<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> \"A\" .\n"), 100)
+
+			respond := func(encoding string, compress func(io.Writer) io.WriteCloser) {
+				var compressed bytes.Buffer
+				writer := compress(&compressed)
+				writer.Write(body)
+				writer.Close()
+
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewBytesResponse(200, compressed.Bytes())
+					resp.Header.Set("Content-Encoding", encoding)
+
+					return resp, nil
+				})
+			}
+
+			It("decodes gzip", func() {
+				respond("gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Body).To(Equal(body))
+				Expect(resp.DecompressedBytes).To(Equal(int64(len(body))))
+				Expect(resp.CompressedBytes).To(BeNumerically("<", len(body)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("decodes zlib wrapped deflate", func() {
+				respond("deflate", func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Body).To(Equal(body))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("decodes raw deflate", func() {
+				respond("deflate", func(w io.Writer) io.WriteCloser {
+					writer, _ := flate.NewWriter(w, flate.DefaultCompression)
+					return writer
+				})
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Body).To(Equal(body))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("decodes brotli", func() {
+				respond("br", func(w io.Writer) io.WriteCloser { return brotli.NewWriter(w) })
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Body).To(Equal(body))
+				Expect(resp.CompressedBytes).To(BeNumerically("<", len(body)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("decodes bodies when the Accept-Encoding header is given", func() {
+				respond("gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+				resp, err := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Accept-Encoding", Value: "gzip"}}})
+
+				Expect(resp.Body).To(Equal(body))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("applies the body limit to the decoded body", func() {
+				respond("gzip", func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })
+
+				resp, err := net.Get(&GetInput{Uri: uri, MaxBodyBytes: int64(len(body) - 1)})
+
+				Expect(resp.ErrorCode).To(Equal(net.BodyTooLarge))
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("returns an error for an unsupported encoding", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(200, "data")
+					resp.Header.Set("Content-Encoding", "compress")
+
+					return resp, nil
+				})
+
+				_, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(err).To(Equal(&net.UnsupportedEncodingError{Encoding: "compress"}))
+			})
+
+			It("returns an empty body for an empty encoded body", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(200, "")
+					resp.Header.Set("Content-Encoding", "gzip")
+
+					return resp, nil
+				})
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(resp.Body).To(BeEmpty())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("retries a retryable status with an empty encoded body", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(503, "")
+					resp.Header.Set("Content-Encoding", "gzip")
+
+					return resp, nil
+				})
+
+				resp, err := net.Get(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 2, BackoffBase: 1}})
+
+				Expect(resp.Attempts).To(Equal(int32(2)))
+				Expect(resp.StatusCode).To(Equal(int32(503)))
+				Expect(resp.ErrorKind).To(Equal(net.HTTPStatusError))
+				Expect(resp.Retryable).To(BeTrue())
+				Expect(err).To(MatchError("Received 503 status code from " + uri + ": "))
+			})
+
+			It("reports the status of a rejected response whose body cannot be decoded", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(503, "Service Unavailable")
+					resp.Header.Set("Content-Encoding", "gzip")
+
+					return resp, nil
+				})
+
+				resp, err := net.Get(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 2, BackoffBase: 1}})
+
+				Expect(resp.Attempts).To(Equal(int32(2)))
+				Expect(resp.ErrorKind).To(Equal(net.HTTPStatusError))
+				Expect(err).To(MatchError("Received 503 status code from " + uri + ": "))
+			})
+		})
+
+		Context("with a body limit", func() {
+			uri := "https://api.parliament.uk/query/person_index"
+
+			BeforeEach(func() {
+				httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(200, "0123456789"))
+			})
+
+			It("allows a body up to the limit", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, MaxBodyBytes: 10})
+
+				Expect(resp.Body).To(Equal([]byte("0123456789")))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("aborts with a typed error once the limit is passed", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, MaxBodyBytes: 4, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 1}})
+
+				Expect(resp.Body).To(BeNil())
+				Expect(resp.ErrorCode).To(Equal(net.BodyTooLarge))
+				Expect(resp.ErrorKind).To(Equal(net.LimitError))
+				Expect(resp.Retryable).To(BeFalse())
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(err).To(Equal(&net.BodyTooLargeError{Uri: uri, Max: 4}))
+				Expect(err).To(MatchError("Body from https://api.parliament.uk/query/person_index is larger than the limit of 4 bytes"))
+			})
+
+			It("aborts before reading a body declared to be too large", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(200, "0123456789")
+					resp.ContentLength = 10
+
+					return resp, nil
+				})
+
+				resp, err := net.Get(&GetInput{Uri: uri, MaxBodyBytes: 4})
+
+				Expect(resp.ErrorCode).To(Equal(net.BodyTooLarge))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Context("when streaming", func() {
+			uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+
+			BeforeEach(func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(200, "streamed")
+					resp.Header.Set("Content-Type", "application/n-triples")
+
+					return resp, nil
+				})
+			})
+
+			It("hands the body to the consumer without buffering it", func() {
+				var consumed []byte
+				resp, err := net.Stream(&GetInput{Uri: uri}, func(output *GetOutput, body io.Reader) error {
+					Expect(output.ContentType).To(Equal("application/n-triples"))
+
+					consumed, _ = ioutil.ReadAll(body)
+					return nil
+				})
+
+				Expect(consumed).To(Equal([]byte("streamed")))
+				Expect(resp.Body).To(BeNil())
+				Expect(resp.StatusCode).To(Equal(int32(200)))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("returns the consumer's error without retrying", func() {
+				resp, err := net.Stream(&GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 1}}, func(output *GetOutput, body io.Reader) error {
+					return errors.New("could not process")
+				})
+
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(resp.Error).To(Equal("could not process"))
+				Expect(err).To(MatchError("could not process"))
+			})
+		})
+
+		Context("with a SPARQL query", func() {
+			uri := "http://localhost:7200/repositories/parliament"
+			query := "CONSTRUCT { ?s ?p ?o } WHERE { ?s ?p ?o } LIMIT 1"
+			var requests []*http.Request
+			var bodies []string
+
+			BeforeEach(func() {
+				requests, bodies = nil, nil
+				httpmock.RegisterResponder("POST", uri, func(req *http.Request) (*http.Response, error) {
+					body, _ := ioutil.ReadAll(req.Body)
+					requests = append(requests, req)
+					bodies = append(bodies, string(body))
+
+					resp := httpmock.NewStringResponse(200, "<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> \"One\" .")
+					resp.Header.Set("Cache-Control", "public, max-age=60")
+
+					return resp, nil
+				})
+			})
+
+			It("posts the query form encoded", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: query}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(int32(200)))
+				Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/x-www-form-urlencoded"))
+				Expect(requests[0].Header.Get("Accept")).To(Equal(net.SparqlGraphAccept))
+				Expect(bodies[0]).To(Equal("query=CONSTRUCT+%7B+%3Fs+%3Fp+%3Fo+%7D+WHERE+%7B+%3Fs+%3Fp+%3Fo+%7D+LIMIT+1"))
+			})
+
+			It("posts the query directly", func() {
+				_, err := net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: query, Encoding: net.DirectEncoding}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/sparql-query"))
+				Expect(bodies[0]).To(Equal(query))
+			})
+
+			It("asks for a result set for SELECT and ASK queries", func() {
+				_, err := net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: "# people\nPREFIX schema: <https://id.parliament.uk/schema/>\nselect ?person WHERE { ?person a schema:Person }"}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requests[0].Header.Get("Accept")).To(Equal(net.SparqlResultsAccept))
+			})
+
+			It("keeps an Accept header that is given", func() {
+				_, err := net.Get(&GetInput{Uri: uri, Headers: []*GetInput_Header{{Key: "Accept", Value: "text/turtle"}}, Sparql: &GetInput_SparqlQuery{Query: query}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(requests[0].Header["Accept"]).To(Equal([]string{"text/turtle"}))
+			})
+
+			It("does not cache the response", func() {
+				Expect(net.Configure(&net.ClientConfig{CacheEntries: 2})).To(Succeed())
+				defer net.Configure(&net.ClientConfig{})
+
+				net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: query}})
+				resp, err := net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: query}})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Cache).To(BeEmpty())
+				Expect(requests).To(HaveLen(2))
+			})
+
+			It("returns an error for an unknown encoding", func() {
+				resp, err := net.Get(&GetInput{Uri: uri, Sparql: &GetInput_SparqlQuery{Query: query, Encoding: "multipart"}})
+
+				Expect(err).To(MatchError("Unknown SPARQL query encoding multipart"))
+				Expect(resp.Retryable).To(BeFalse())
+				Expect(requests).To(BeEmpty())
+			})
+		})
+
+		Context("with a context", func() {
+			uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+
+			It("stops a request in flight once cancelled", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					<-req.Context().Done()
+
+					return nil, req.Context().Err()
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+
+				resp, err := net.GetContext(ctx, &GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 1}})
+
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(resp.ErrorKind).To(Equal(net.CanceledError))
+				Expect(resp.Retryable).To(BeFalse())
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("stops waiting to retry once cancelled", func() {
+				httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(503, "Service Unavailable"))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+
+				start := time.Now()
+				resp, err := net.GetContext(ctx, &GetInput{Uri: uri, Retry: &GetInput_RetryPolicy{MaxAttempts: 3, BackoffBase: 60000}})
+
+				Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+				Expect(resp.Attempts).To(Equal(int32(1)))
+				Expect(resp.ErrorKind).To(Equal(net.CanceledError))
+				Expect(err).To(Equal(context.Canceled))
+			})
+
+			It("reports a deadline as a timeout", func() {
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					<-req.Context().Done()
+
+					return nil, req.Context().Err()
+				})
+
+				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+				defer cancel()
+
+				resp, err := net.GetContext(ctx, &GetInput{Uri: uri})
+
+				Expect(resp.ErrorKind).To(Equal(net.TimeoutError))
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Context("with an invalid URI", func() {
 			BeforeEach(func() {
 				httpmock.DeactivateAndReset()
@@ -154,11 +854,179 @@ var _ = Describe("Net", func() {
 
 				resp, err := net.Get(&GetInput{Uri: uri})
 
-				Expect(resp).To(Equal(&GetOutput{Uri: "some_invalid-value.foo", Error: errorString}))
+				Expect(resp).To(Equal(&GetOutput{Uri: "some_invalid-value.foo", Error: errorString, Attempts: 1, AttemptErrors: []string{errorString}, ErrorKind: net.NetworkError, Retryable: false}))
 
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal(errorString))
 			})
 		})
 	})
+
+	Describe("Do", func() {
+		uri := "https://api.parliament.uk/graph/43RHonMf"
+		body := `<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .`
+		var requests []*http.Request
+		var bodies []string
+		var statusCodes []int
+
+		BeforeEach(func() {
+			requests, bodies, statusCodes = nil, nil, nil
+			respond := func(req *http.Request) (*http.Response, error) {
+				var received []byte
+				if req.Body != nil {
+					received, _ = ioutil.ReadAll(req.Body)
+				}
+				requests = append(requests, req)
+				bodies = append(bodies, string(received))
+
+				statusCode := 204
+				if len(statusCodes) > 0 {
+					statusCode, statusCodes = statusCodes[0], statusCodes[1:]
+				}
+
+				return httpmock.NewStringResponse(statusCode, ""), nil
+			}
+
+			for _, method := range []string{"POST", "PUT", "PATCH", "DELETE"} {
+				httpmock.RegisterResponder(method, uri, respond)
+			}
+		})
+
+		It("sends the method, body and content type", func() {
+			resp, err := net.Do(&Request{Method: "put", Uri: uri, Body: []byte(body), ContentType: "application/n-triples"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(int32(204)))
+			Expect(requests[0].Method).To(Equal("PUT"))
+			Expect(requests[0].Header.Get("Content-Type")).To(Equal("application/n-triples"))
+			Expect(bodies).To(Equal([]string{body}))
+		})
+
+		It("sends requests without a body", func() {
+			resp, err := net.Do(&Request{Method: "DELETE", Uri: uri})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(int32(204)))
+			Expect(requests[0].Header.Get("Content-Type")).To(BeEmpty())
+			Expect(bodies).To(Equal([]string{""}))
+		})
+
+		It("sends the body again on each attempt", func() {
+			statusCodes = []int{503, 201}
+
+			resp, err := net.Do(&Request{Method: "POST", Uri: uri, Body: []byte(body), Retry: &GetInput_RetryPolicy{MaxAttempts: 2, BackoffBase: 1}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Attempts).To(Equal(int32(2)))
+			Expect(bodies).To(Equal([]string{body, body}))
+		})
+
+		It("reports failures as Get does", func() {
+			statusCodes = []int{409}
+
+			resp, err := net.Do(&Request{Method: "PATCH", Uri: uri, Body: []byte(body)})
+
+			Expect(err).To(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(int32(409)))
+			Expect(resp.ErrorKind).To(Equal(net.HTTPStatusError))
+			Expect(resp.Retryable).To(BeFalse())
+		})
+
+		Context("with a response cache", func() {
+			BeforeEach(func() {
+				Expect(net.Configure(&net.ClientConfig{CacheEntries: 2})).To(Succeed())
+
+				httpmock.RegisterResponder("GET", uri, func(req *http.Request) (*http.Response, error) {
+					resp := httpmock.NewStringResponse(200, body)
+					resp.Header.Set("Cache-Control", "public, max-age=60")
+
+					return resp, nil
+				})
+			})
+
+			AfterEach(func() {
+				Expect(net.Configure(&net.ClientConfig{})).To(Succeed())
+			})
+
+			It("stops serving the cached response once a write succeeds", func() {
+				net.Get(&GetInput{Uri: uri})
+				_, err := net.Do(&Request{Method: "PUT", Uri: uri, Body: []byte(body)})
+				Expect(err).NotTo(HaveOccurred())
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Cache).To(Equal(net.CacheMiss))
+			})
+
+			It("keeps serving the cached response when a write fails", func() {
+				statusCodes = []int{409}
+
+				net.Get(&GetInput{Uri: uri})
+				net.Do(&Request{Method: "PUT", Uri: uri, Body: []byte(body)})
+
+				resp, err := net.Get(&GetInput{Uri: uri})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.Cache).To(Equal(net.CacheHit))
+			})
+		})
+	})
+
+	Describe("Configure", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/slow" {
+					time.Sleep(200 * time.Millisecond)
+				}
+
+				w.Write([]byte(r.URL.String()))
+			}))
+		})
+
+		AfterEach(func() {
+			server.Close()
+			Expect(net.Configure(&net.ClientConfig{})).To(Succeed())
+		})
+
+		It("reuses the configured client", func() {
+			Expect(net.Configure(&net.ClientConfig{Timeout: 1000})).To(Succeed())
+
+			Expect(net.Client()).To(BeIdenticalTo(net.Client()))
+			Expect(net.Client().Timeout).To(Equal(time.Second))
+		})
+
+		It("times out waiting for a response", func() {
+			Expect(net.Configure(&net.ClientConfig{ReadTimeout: 50})).To(Succeed())
+
+			resp, err := net.Get(&GetInput{Uri: server.URL + "/slow"})
+
+			Expect(err).To(HaveOccurred())
+			Expect(resp.Error).To(ContainSubstring("timeout awaiting response headers"))
+			Expect(resp.ErrorKind).To(Equal(net.TimeoutError))
+		})
+
+		It("sends requests through a proxy", func() {
+			Expect(net.Configure(&net.ClientConfig{ProxyURL: server.URL})).To(Succeed())
+
+			resp, err := net.Get(&GetInput{Uri: "http://api.parliament.uk/query/person_by_id"})
+
+			Expect(string(resp.Body)).To(Equal("http://api.parliament.uk/query/person_by_id"))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns an error for a missing CA file", func() {
+			err := net.Configure(&net.ClientConfig{CAFile: "/no/such/file.pem"})
+
+			Expect(err).To(MatchError(HavePrefix("Error reading CA file /no/such/file.pem")))
+		})
+
+		It("returns an error for a certificate without a key", func() {
+			err := net.Configure(&net.ClientConfig{CertFile: "client.pem"})
+
+			Expect(err).To(MatchError("Both a certificate and a key file are needed for client certificates"))
+		})
+	})
 })
@@ -0,0 +1,34 @@
+package net
+
+import (
+	"net/http"
+)
+
+// DefaultAcceptedStatusCodes are treated as success when a request does not list its own
+var DefaultAcceptedStatusCodes = []int32{http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent, http.StatusNotModified}
+
+// DefaultWriteStatusCodes are treated as success as well as the defaults for methods other than GET
+var DefaultWriteStatusCodes = []int32{http.StatusCreated, http.StatusAccepted}
+
+// accepted reports whether statusCode is one of statusCodes, or of the defaults for method when none are given
+func accepted(statusCodes []int32, method string, statusCode int) bool {
+	if len(statusCodes) == 0 {
+		statusCodes = DefaultAcceptedStatusCodes
+		if method != "GET" {
+			statusCodes = append(append([]int32{}, statusCodes...), DefaultWriteStatusCodes...)
+		}
+	}
+
+	for _, accepted := range statusCodes {
+		if int(accepted) == statusCode {
+			return true
+		}
+	}
+
+	return false
+}
+
+// empty reports whether a response with statusCode never carries a body
+func empty(statusCode int) bool {
+	return statusCode == http.StatusNoContent || statusCode == http.StatusNotModified
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"github.com/ukparliament/gromnative/ext/net"
+	"github.com/ukparliament/gromnative/ext/processor"
+	netType "github.com/ukparliament/gromnative/ext/types/net"
+	"log"
+)
+
+// DefaultMaxPages is the most pages followed when a request's pagination does not say
+const DefaultMaxPages = 20
+
+// Pagination follows links to the next page, from a Link header with rel="next" or a hydra:next triple,
+// merging every page into one graph
+type Pagination struct {
+	// MaxPages includes the first page, defaulting to DefaultMaxPages
+	MaxPages int32 `json:"maxPages"`
+}
+
+// pageError marks an error fetching a page as it passes back through the processor
+type pageError struct {
+	error
+	output *netType.GetOutput
+}
+
+// getandProcessPages fetches request and the pages that follow it, decoding each page once it arrives.
+// The response carries the status code of the last page fetched and the attempts made across every page.
+func getandProcessPages(ctx context.Context, request *Request) (Response, error) {
+	response := Response{ Uri: request.Uri }
+
+	maxPages := request.Pagination.MaxPages
+	if maxPages < 1 {
+		maxPages = DefaultMaxPages
+	}
+
+	// Used to stop at pages that link back to one already fetched
+	seen := make(map[string]bool)
+	// The next page from the Link header of the last page fetched
+	var next string
+
+	fetch := func(uri string) (*processor.Document, error) {
+		seen[uri] = true
+
+		input := request.getInput()
+		input.Uri = uri
+
+		log.Printf("Requesting page %v: %v\n", response.Pages+1, uri)
+		output, err := net.GetContext(ctx, input)
+
+		response.StatusCode = output.StatusCode
+		response.Attempts += output.Attempts
+		if err != nil {
+			return nil, pageError{err, output}
+		}
+
+		response.Pages++
+		response.Cache = output.Cache
+
+		// A Link header takes precedence over any hydra:next triple in the body
+		next = output.Next
+
+		return &processor.Document{Body: output.Body, ContentType: output.ContentType}, nil
+	}
+
+	first, err := fetch(request.Uri)
+	if err != nil {
+		return pageFailed(response, err.(pageError))
+	}
+
+	processedData, err := processor.ProcessContext(ctx, &processor.ProcessorInput{
+		Documents:  []processor.Document{*first},
+		Types:      request.Filter,
+		EdgeNaming: processor.EdgeNaming(request.EdgeNaming),
+		Prefixes:   request.Prefixes,
+		MaxTriples: request.MaxTriples,
+		NextDocument: func(hydraNext string) (*processor.Document, error) {
+			uri := next
+			if uri == "" {
+				uri = hydraNext
+			}
+
+			if uri == "" || seen[uri] || response.Pages >= maxPages {
+				return nil, nil
+			}
+
+			return fetch(uri)
+		},
+	})
+
+	if failed, ok := err.(pageError); ok {
+		return pageFailed(response, failed)
+	}
+
+	if err != nil {
+		log.Printf("Error processing: %v\n", err)
+		response.Err = processedData.Error
+		response.ErrorCode = processedData.ErrorCode
+		response.ErrorDetails = processError(ctx, processedData, request.Uri)
+		return response, err
+	}
+
+	response.StatementsBySubject = processedData.StatementsBySubject
+	response.EdgesBySubject = processedData.EdgesBySubject
+	response.SubjectsByType = processedData.SubjectsByType
+
+	log.Printf("Done after %v pages\n", response.Pages)
+
+	return response, nil
+}
+
+// pageFailed reports the failure to fetch a page in response
+func pageFailed(response Response, failed pageError) (Response, error) {
+	log.Printf("Error getting: %v\n", failed.error)
+	response.Err = failed.output.Error
+	response.ErrorCode = failed.output.ErrorCode
+	response.ErrorDetails = getError(failed.output)
+
+	return response, failed.error
+}
@@ -0,0 +1,210 @@
+package processor
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// CacheStats describes the processed graph cache
+type CacheStats struct {
+	Entries   int   `json:"entries"`
+	Bytes     int64 `json:"bytes"`
+	MaxBytes  int64 `json:"maxBytes"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"`
+}
+
+type graphCacheEntry struct {
+	key    [sha256.Size]byte
+	output *ProcessorOutput
+	size   int64
+}
+
+// graphCache is an LRU of processed graphs bounded by their estimated size in memory
+type graphCache struct {
+	mutex   sync.Mutex
+	order   *list.List
+	entries map[[sha256.Size]byte]*list.Element
+	stats   CacheStats
+}
+
+var (
+	graphCacheMutex sync.RWMutex
+	sharedCache     *graphCache
+)
+
+// ConfigureCache enables a processed graph cache holding up to maxBytes, or disables it when maxBytes is 0.
+// Outputs served from the cache are shared, so callers must not modify them.
+func ConfigureCache(maxBytes int64) {
+	graphCacheMutex.Lock()
+	defer graphCacheMutex.Unlock()
+
+	sharedCache = nil
+	if maxBytes > 0 {
+		sharedCache = &graphCache{
+			order:   list.New(),
+			entries: make(map[[sha256.Size]byte]*list.Element),
+			stats:   CacheStats{MaxBytes: maxBytes},
+		}
+	}
+}
+
+// CacheStatistics returns the processed graph cache statistics, which are all zero when the cache is disabled
+func CacheStatistics() CacheStats {
+	cache := processedCache()
+	if cache == nil {
+		return CacheStats{}
+	}
+
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	return cache.stats
+}
+
+func processedCache() *graphCache {
+	graphCacheMutex.RLock()
+	defer graphCacheMutex.RUnlock()
+
+	return sharedCache
+}
+
+func (c *graphCache) get(key [sha256.Size]byte) (*ProcessorOutput, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.stats.Misses++
+		return nil, false
+	}
+
+	c.stats.Hits++
+	c.order.MoveToFront(element)
+
+	return element.Value.(*graphCacheEntry).output, true
+}
+
+func (c *graphCache) add(key [sha256.Size]byte, output *ProcessorOutput) {
+	size := estimateSize(output)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Graphs larger than the whole cache are never stored
+	if size > c.stats.MaxBytes {
+		return
+	}
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+
+	c.entries[key] = c.order.PushFront(&graphCacheEntry{key: key, output: output, size: size})
+	c.stats.Entries++
+	c.stats.Bytes += size
+
+	for c.stats.Bytes > c.stats.MaxBytes {
+		c.remove(c.order.Back())
+		c.stats.Evictions++
+	}
+}
+
+func (c *graphCache) remove(element *list.Element) {
+	entry := element.Value.(*graphCacheEntry)
+
+	c.order.Remove(element)
+	delete(c.entries, entry.key)
+	c.stats.Entries--
+	c.stats.Bytes -= entry.size
+}
+
+// cacheKey hashes everything that can change the output of Process
+func cacheKey(input *ProcessorInput) [sha256.Size]byte {
+	hash := sha256.New()
+	write := func(value string) {
+		binary.Write(hash, binary.LittleEndian, int64(len(value)))
+		hash.Write([]byte(value))
+	}
+
+	documents := input.Documents
+	if len(documents) == 0 {
+		write("single")
+		documents = []Document{{Body: input.Body, ContentType: input.ContentType}}
+	}
+	for _, document := range documents {
+		write(string(document.Body))
+		write(document.ContentType)
+	}
+
+	write("types")
+	for _, t := range input.Types {
+		write(t)
+	}
+
+	write(string(input.EdgeNaming))
+	write(strconv.FormatInt(input.MaxTriples, 10))
+	prefixes := make([]string, 0, len(input.Prefixes))
+	for prefix := range input.Prefixes {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+	for _, prefix := range prefixes {
+		write(prefix)
+		write(input.Prefixes[prefix])
+	}
+
+	var key [sha256.Size]byte
+	copy(key[:], hash.Sum(nil))
+
+	return key
+}
+
+// estimateSize roughly counts the bytes held by an output, including string and slice headers
+func estimateSize(output *ProcessorOutput) int64 {
+	const stringHeader, sliceHeader, mapEntry = 16, 24, 48
+
+	size := int64(0)
+	for subject, triples := range output.StatementsBySubject {
+		size += mapEntry + int64(len(subject)) + sliceHeader
+		for _, triple := range triples {
+			object := triple.Object
+			size += 6*stringHeader + int64(len(triple.Subject)+len(triple.Predicate)+len(object.Value)+len(object.Datatype)+len(object.Language))
+		}
+	}
+
+	for subject, edges := range output.EdgesBySubject {
+		size += mapEntry + int64(len(subject))
+		for predicate, objects := range edges {
+			size += mapEntry + int64(len(predicate)) + sliceHeader
+			for _, object := range objects {
+				size += stringHeader + int64(len(object))
+			}
+		}
+	}
+
+	for _, subjects := range output.SubjectsByType {
+		size += sliceHeader
+		for _, subject := range subjects {
+			size += stringHeader + int64(len(subject))
+		}
+	}
+
+	if results := output.Results; results != nil {
+		for _, variable := range results.Variables {
+			size += stringHeader + int64(len(variable))
+		}
+		for _, row := range results.Rows {
+			for variable, term := range row.Bindings {
+				size += mapEntry + 4*stringHeader + int64(len(variable)+len(term.Value)+len(term.Datatype)+len(term.Language))
+			}
+		}
+	}
+
+	return size
+}
@@ -0,0 +1,347 @@
+package processor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Decoder reads statements from r, passing each one to emit as it is decoded.
+// Decoding stops at the first error returned by emit.
+type Decoder func(r io.Reader, emit func(Statement) error) error
+
+var (
+	decodersMutex sync.RWMutex
+	decoders      = map[string]Decoder{
+		"application/n-triples": DecodeNTriples,
+		"application/ntriples":  DecodeNTriples,
+		"application/n-quads":   DecodeNQuads,
+		"text/x-nquads":         DecodeNQuads,
+		"text/turtle":           DecodeTurtle,
+		"application/x-turtle":  DecodeTurtle,
+		"application/ld+json":   DecodeJSONLD,
+		"application/rdf+xml":   DecodeRDFXML,
+	}
+)
+
+// RegisterDecoder makes a decoder available for responses of the given media type
+func RegisterDecoder(mediaType string, decoder Decoder) {
+	decodersMutex.Lock()
+	defer decodersMutex.Unlock()
+
+	decoders[strings.ToLower(mediaType)] = decoder
+}
+
+// DecoderFor picks a decoder from a Content-Type header, sniffing the body when the header is missing or unknown
+func DecoderFor(contentType string, body []byte) Decoder {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		decodersMutex.RLock()
+		decoder, ok := decoders[strings.ToLower(mediaType)]
+		decodersMutex.RUnlock()
+
+		if ok {
+			return decoder
+		}
+	}
+
+	return sniff(body)
+}
+
+// sniff guesses the syntax of a body from its first few hundred bytes, defaulting to N-Triples
+func sniff(body []byte) Decoder {
+	head := body
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+
+	switch {
+	case len(head) > 0 && (head[0] == '{' || head[0] == '['):
+		if bytes.Contains(head, []byte(`"@context"`)) || bytes.Contains(head, []byte(`"@id"`)) || bytes.Contains(head, []byte(`"@graph"`)) {
+			return DecodeJSONLD
+		}
+	case bytes.HasPrefix(head, []byte("<?xml")) || bytes.HasPrefix(head, []byte("<rdf:RDF")):
+		return DecodeRDFXML
+	case bytes.HasPrefix(head, []byte("@prefix")) || bytes.HasPrefix(head, []byte("@base")):
+		return DecodeTurtle
+	}
+
+	upper := bytes.ToUpper(head)
+	if bytes.HasPrefix(upper, []byte("PREFIX")) || bytes.HasPrefix(upper, []byte("BASE")) {
+		return DecodeTurtle
+	}
+
+	return DecodeNTriples
+}
+
+// DecodeNTriples reads N-Triples a line at a time, so statements are emitted as the body arrives.
+// Errors are worded as they were by the triplestore decoder this replaced.
+func DecodeNTriples(r io.Reader, emit func(Statement) error) error {
+	return decodeLines(r, "lenient parsing", false, emit)
+}
+
+// DecodeNQuads reads N-Quads, folding every graph into one
+func DecodeNQuads(r io.Reader, emit func(Statement) error) error {
+	return decodeLines(r, "n-quads", true, emit)
+}
+
+func decodeLines(r io.Reader, name string, quads bool, emit func(Statement) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		statement, ok, err := parseLine(scanner.Text(), quads)
+		if err != nil {
+			return fmt.Errorf("%v: line %d: %v", name, line, err)
+		}
+		if !ok {
+			continue
+		}
+
+		if err := emit(statement); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseLine reads a single N-Triples line, or N-Quads line when quads is set, reporting false for blank and comment lines
+func parseLine(line string, quads bool) (Statement, bool, error) {
+	l := &lineReader{text: line}
+
+	l.skipSpace()
+	if l.done() || l.peek() == '#' {
+		return Statement{}, false, nil
+	}
+
+	subject, err := l.term()
+	if err != nil || subject.Kind == Literal {
+		return Statement{}, false, fmt.Errorf("invalid subject in %s", line)
+	}
+
+	predicate, err := l.term()
+	if err != nil || predicate.Kind != IRI {
+		return Statement{}, false, fmt.Errorf("invalid predicate in %s", line)
+	}
+
+	object, err := l.term()
+	if err != nil {
+		return Statement{}, false, fmt.Errorf("invalid object in %s", line)
+	}
+
+	// The graph label is optional and is dropped
+	l.skipSpace()
+	if quads && !l.done() && l.peek() != '.' {
+		if graph, err := l.term(); err != nil || graph.Kind == Literal {
+			return Statement{}, false, fmt.Errorf("invalid graph label in %s", line)
+		}
+	}
+
+	l.skipSpace()
+	if l.done() || l.peek() != '.' {
+		return Statement{}, false, fmt.Errorf("missing '.' in %s", line)
+	}
+
+	return Statement{Subject: subject, Predicate: predicate.Value, Object: object}, true, nil
+}
+
+// lineReader tokenises the terms of a single N-Triples or N-Quads line
+type lineReader struct {
+	text string
+	pos  int
+}
+
+func (l *lineReader) done() bool {
+	return l.pos >= len(l.text)
+}
+
+func (l *lineReader) peek() byte {
+	if l.done() {
+		return 0
+	}
+
+	return l.text[l.pos]
+}
+
+func (l *lineReader) skipSpace() {
+	for !l.done() && (l.peek() == ' ' || l.peek() == '\t' || l.peek() == '\r') {
+		l.pos++
+	}
+}
+
+func (l *lineReader) term() (Term, error) {
+	l.skipSpace()
+	if l.done() {
+		return Term{}, io.ErrUnexpectedEOF
+	}
+
+	switch l.peek() {
+	case '<':
+		end := strings.IndexByte(l.text[l.pos:], '>')
+		if end < 0 {
+			return Term{}, fmt.Errorf("unterminated IRI")
+		}
+
+		value, err := unescape(l.text[l.pos+1 : l.pos+end])
+		l.pos += end + 1
+
+		return NewIRI(value), err
+	case '_':
+		if !strings.HasPrefix(l.text[l.pos:], "_:") {
+			return Term{}, fmt.Errorf("invalid blank node")
+		}
+
+		start := l.pos + 2
+		l.pos = start
+		for !l.done() && l.peek() != ' ' && l.peek() != '\t' && l.peek() != '.' {
+			l.pos++
+		}
+
+		return NewBlankNode(l.text[start:l.pos]), nil
+	case '"':
+		l.pos++
+		start := l.pos
+		for !l.done() && l.peek() != '"' {
+			if l.peek() == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.done() {
+			return Term{}, fmt.Errorf("unterminated literal")
+		}
+
+		value, err := unescape(l.text[start:l.pos])
+		if err != nil {
+			return Term{}, err
+		}
+		l.pos++
+
+		if strings.HasPrefix(l.text[l.pos:], "@") {
+			start := l.pos + 1
+			l.pos = start
+			for !l.done() && l.peek() != ' ' && l.peek() != '\t' && l.peek() != '.' {
+				l.pos++
+			}
+
+			return NewLiteral(value, "", l.text[start:l.pos]), nil
+		}
+
+		if strings.HasPrefix(l.text[l.pos:], "^^") {
+			l.pos += 2
+
+			datatype, err := l.term()
+			if err != nil || datatype.Kind != IRI {
+				return Term{}, fmt.Errorf("invalid datatype")
+			}
+
+			return NewLiteral(value, datatype.Value, ""), nil
+		}
+
+		return NewLiteral(value, "", ""), nil
+	}
+
+	return Term{}, fmt.Errorf("unexpected %q", l.peek())
+}
+
+// generatedLabel starts the labels decoders give anonymous blank nodes
+const generatedLabel = "genid"
+
+// generatedBlankNode returns the nth anonymous blank node of a document
+func generatedBlankNode(n int) Term {
+	return NewBlankNode(generatedLabel + strconv.Itoa(n))
+}
+
+// labelledBlankNode returns the blank node for a label written in a document. Labels that could be taken for
+// generated ones gain a further prefix, so a document's own labels never collide with its anonymous nodes.
+func labelledBlankNode(label string) Term {
+	if strings.HasPrefix(label, generatedLabel) {
+		label = generatedLabel + "-" + label
+	}
+
+	return NewBlankNode(label)
+}
+
+// emitList writes the rdf:first/rdf:rest chain for a collection, returning its head
+func emitList(items []Term, newBlankNode func() Term, emit func(Statement) error) (Term, error) {
+	head := NewIRI(rdfNamespace + "nil")
+
+	for i := len(items) - 1; i >= 0; i-- {
+		node := newBlankNode()
+
+		if err := emit(Statement{Subject: node, Predicate: rdfNamespace + "first", Object: items[i]}); err != nil {
+			return Term{}, err
+		}
+		if err := emit(Statement{Subject: node, Predicate: rdfNamespace + "rest", Object: head}); err != nil {
+			return Term{}, err
+		}
+
+		head = node
+	}
+
+	return head, nil
+}
+
+// unescape resolves the string escapes shared by N-Triples, N-Quads and Turtle
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("invalid escape at end of %q", s)
+		}
+
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'b':
+			b.WriteByte('\b')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case '"', '\'', '\\':
+			b.WriteByte(s[i])
+		case 'u', 'U':
+			size := 4
+			if s[i] == 'U' {
+				size = 8
+			}
+			if i+size >= len(s) {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+
+			r, err := strconv.ParseUint(s[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape in %q", s)
+			}
+
+			b.WriteRune(rune(r))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape \\%c in %q", s[i], s)
+		}
+	}
+
+	return b.String(), nil
+}
@@ -0,0 +1,70 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EdgeNaming decides how a predicate is turned into a key in EdgesBySubject
+type EdgeNaming string
+
+const (
+	// LastSegment keys edges by whatever follows the final '/', e.g. rdf-syntax-ns#type
+	LastSegment EdgeNaming = "last_segment"
+	// LocalName keys edges by whatever follows the final '/' or '#', e.g. type
+	LocalName EdgeNaming = "local_name"
+	// Curie keys edges by a compact IRI built from the supplied prefixes, e.g. rdf:type
+	Curie EdgeNaming = "curie"
+	// FullURI keys edges by the predicate itself
+	FullURI EdgeNaming = "full_uri"
+)
+
+// edgeNamer returns a function naming edges with the given strategy, defaulting to LastSegment
+func edgeNamer(naming EdgeNaming, prefixes map[string]string) (func(string) string, error) {
+	switch naming {
+	case "", LastSegment:
+		return lastSegment, nil
+	case LocalName:
+		return localName, nil
+	case Curie:
+		return func(predicate string) string {
+			return curie(predicate, prefixes)
+		}, nil
+	case FullURI:
+		return func(predicate string) string {
+			return predicate
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unknown edge naming %q", naming)
+}
+
+func lastSegment(predicate string) string {
+	predicateSlice := strings.Split(predicate, "/")
+	return predicateSlice[len(predicateSlice)-1]
+}
+
+func localName(predicate string) string {
+	return predicate[strings.LastIndexAny(predicate, "/#")+1:]
+}
+
+// curie compacts the predicate using the longest matching namespace, falling back to the full URI
+func curie(predicate string, prefixes map[string]string) string {
+	bestPrefix, bestNamespace := "", ""
+
+	for prefix, namespace := range prefixes {
+		if namespace == "" || !strings.HasPrefix(predicate, namespace) {
+			continue
+		}
+
+		if len(namespace) > len(bestNamespace) || (len(namespace) == len(bestNamespace) && prefix < bestPrefix) {
+			bestPrefix, bestNamespace = prefix, namespace
+		}
+	}
+
+	if bestNamespace == "" {
+		return predicate
+	}
+
+	return bestPrefix + ":" + strings.TrimPrefix(predicate, bestNamespace)
+}
@@ -0,0 +1,38 @@
+package processor
+
+import (
+	"github.com/ukparliament/gromnative/ext/types/graph"
+)
+
+// Graph copies the output into a graph.Graph, leaving the output untouched as it may be shared through the cache
+func (output *ProcessorOutput) Graph() *graph.Graph {
+	result := &graph.Graph{
+		StatementsBySubject: make(map[string]*graph.Statements, len(output.StatementsBySubject)),
+		EdgesBySubject:      make(map[string]*graph.Edges, len(output.EdgesBySubject)),
+	}
+
+	for subject, triples := range output.StatementsBySubject {
+		statements := &graph.Statements{Triples: make([]*graph.Triple, len(triples))}
+		for i, triple := range triples {
+			object := *triple.Object
+			statements.Triples[i] = &graph.Triple{Subject: triple.Subject, Predicate: triple.Predicate, Object: &object, SubjectKind: triple.SubjectKind}
+		}
+
+		result.StatementsBySubject[subject] = statements
+	}
+
+	for subject, predicates := range output.EdgesBySubject {
+		edges := &graph.Edges{Edges: make(map[string]*graph.Objects, len(predicates))}
+		for predicate, objects := range predicates {
+			edges.Edges[predicate] = &graph.Objects{Uris: objects}
+		}
+
+		result.EdgesBySubject[subject] = edges
+	}
+
+	for _, subjects := range output.SubjectsByType {
+		result.SubjectsByType = append(result.SubjectsByType, &graph.Subjects{Subjects: subjects})
+	}
+
+	return result
+}
@@ -0,0 +1,485 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DecodeJSONLD reads JSON-LD with embedded contexts. Remote contexts are not fetched, and named graphs
+// are folded into the default graph. Reverse properties and nested arrays are reported as errors.
+func DecodeJSONLD(r io.Reader, emit func(Statement) error) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+
+	var document interface{}
+	if err := dec.Decode(&document); err != nil {
+		return fmt.Errorf("json-ld: %v", err)
+	}
+
+	d := &jsonldDecoder{emit: emit}
+
+	return d.top(document, &jsonldContext{terms: make(map[string]jsonldTerm)})
+}
+
+type jsonldTerm struct {
+	id        string
+	typ       string
+	language  *string
+	container string
+}
+
+type jsonldContext struct {
+	base     *url.URL
+	vocab    string
+	language string
+	terms    map[string]jsonldTerm
+}
+
+type jsonldDecoder struct {
+	bnodes int
+	emit   func(Statement) error
+}
+
+func (d *jsonldDecoder) top(document interface{}, ctx *jsonldContext) error {
+	switch value := document.(type) {
+	case []interface{}:
+		for _, item := range value {
+			if err := d.top(item, ctx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case map[string]interface{}:
+		_, _, err := d.node(value, ctx)
+		return err
+	}
+
+	return fmt.Errorf("json-ld: expected an object or array at the top level")
+}
+
+// node emits the statements for a node object, returning the term that identifies it. It reports false
+// for an object that only wraps a graph, which is not a node in its own right.
+func (d *jsonldDecoder) node(object map[string]interface{}, ctx *jsonldContext) (Term, bool, error) {
+	if local, ok := object["@context"]; ok {
+		var err error
+		if ctx, err = ctx.with(local); err != nil {
+			return Term{}, false, err
+		}
+	}
+
+	if _, ok := object["@reverse"]; ok {
+		return Term{}, false, fmt.Errorf("json-ld: @reverse is not supported")
+	}
+
+	graph, hasGraph := object["@graph"]
+	if hasGraph {
+		items, ok := graph.([]interface{})
+		if !ok {
+			items = []interface{}{graph}
+		}
+
+		for _, item := range items {
+			if node, ok := item.(map[string]interface{}); ok {
+				if _, _, err := d.node(node, ctx); err != nil {
+					return Term{}, false, err
+				}
+			}
+		}
+
+		if !hasProperties(object) {
+			return Term{}, false, nil
+		}
+	}
+
+	var subject Term
+	if id, ok := object["@id"].(string); ok {
+		subject = ctx.resource(id)
+	} else {
+		subject = d.newBlankNode()
+	}
+
+	for _, t := range asArray(object["@type"]) {
+		if typ, ok := t.(string); ok {
+			if err := d.emit(Statement{Subject: subject, Predicate: rdfType, Object: ctx.vocabResource(typ)}); err != nil {
+				return Term{}, false, err
+			}
+		}
+	}
+
+	// Sort keys so blank node labels are stable between runs
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		predicate := ctx.expand(key, true)
+		if predicate == "" || strings.HasPrefix(predicate, "_:") {
+			continue
+		}
+
+		term := ctx.terms[key]
+		values := object[key]
+
+		if term.container == "@list" && !isList(values) {
+			values = map[string]interface{}{"@list": values}
+		}
+
+		if term.container == "@language" {
+			if languages, ok := values.(map[string]interface{}); ok {
+				// Sort languages so statements come out in the same order between runs
+				tags := make([]string, 0, len(languages))
+				for language := range languages {
+					tags = append(tags, language)
+				}
+				sort.Strings(tags)
+
+				for _, language := range tags {
+					for _, v := range asArray(languages[language]) {
+						if s, ok := v.(string); ok {
+							if err := d.emit(Statement{Subject: subject, Predicate: predicate, Object: NewLiteral(s, "", language)}); err != nil {
+								return Term{}, false, err
+							}
+						}
+					}
+				}
+				continue
+			}
+		}
+
+		for _, value := range members(values) {
+			object, ok, err := d.value(value, term, ctx)
+			if err != nil {
+				return Term{}, false, err
+			}
+			if !ok {
+				continue
+			}
+
+			if err := d.emit(Statement{Subject: subject, Predicate: predicate, Object: object}); err != nil {
+				return Term{}, false, err
+			}
+		}
+	}
+
+	return subject, true, nil
+}
+
+// value converts a single property value into a term, reporting false for values that produce nothing
+func (d *jsonldDecoder) value(value interface{}, term jsonldTerm, ctx *jsonldContext) (Term, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return Term{}, false, nil
+	case string:
+		switch term.typ {
+		case "@id":
+			return ctx.resource(v), true, nil
+		case "@vocab":
+			return ctx.vocabResource(v), true, nil
+		case "":
+			language := ctx.language
+			if term.language != nil {
+				language = *term.language
+			}
+			return NewLiteral(v, "", language), true, nil
+		}
+		return NewLiteral(v, term.typ, ""), true, nil
+	case bool:
+		return NewLiteral(strconv.FormatBool(v), xsdNamespace+"boolean", ""), true, nil
+	case json.Number:
+		if term.typ != "" && term.typ != "@id" && term.typ != "@vocab" {
+			return NewLiteral(v.String(), term.typ, ""), true, nil
+		}
+		if _, err := v.Int64(); err == nil {
+			return NewLiteral(v.String(), xsdNamespace+"integer", ""), true, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return Term{}, false, fmt.Errorf("json-ld: invalid number %v", v)
+		}
+		return NewLiteral(strconv.FormatFloat(f, 'E', -1, 64), xsdNamespace+"double", ""), true, nil
+	case map[string]interface{}:
+		if literal, ok := v["@value"]; ok {
+			return d.valueObject(literal, v, ctx)
+		}
+
+		if list, ok := v["@list"]; ok {
+			return d.list(members(list), term, ctx)
+		}
+
+		if _, ok := v["@set"]; ok {
+			return Term{}, false, fmt.Errorf("json-ld: nested @set is not supported")
+		}
+
+		return d.node(v, ctx)
+	case []interface{}:
+		return Term{}, false, fmt.Errorf("json-ld: nested arrays are not supported")
+	}
+
+	return Term{}, false, fmt.Errorf("json-ld: unsupported value %v", value)
+}
+
+func (d *jsonldDecoder) valueObject(literal interface{}, object map[string]interface{}, ctx *jsonldContext) (Term, bool, error) {
+	if literal == nil {
+		return Term{}, false, nil
+	}
+
+	var text string
+	switch l := literal.(type) {
+	case string:
+		text = l
+	case json.Number:
+		text = l.String()
+	case bool:
+		text = strconv.FormatBool(l)
+	default:
+		return Term{}, false, fmt.Errorf("json-ld: invalid @value %v", literal)
+	}
+
+	if typ, ok := object["@type"].(string); ok {
+		return NewLiteral(text, ctx.expand(typ, true), ""), true, nil
+	}
+
+	language, _ := object["@language"].(string)
+	return NewLiteral(text, "", language), true, nil
+}
+
+// list emits an rdf:first/rdf:rest chain for the items, returning its head
+func (d *jsonldDecoder) list(items []interface{}, term jsonldTerm, ctx *jsonldContext) (Term, bool, error) {
+	var terms []Term
+	for _, item := range items {
+		t, ok, err := d.value(item, term, ctx)
+		if err != nil {
+			return Term{}, false, err
+		}
+		if ok {
+			terms = append(terms, t)
+		}
+	}
+
+	head, err := emitList(terms, d.newBlankNode, d.emit)
+	return head, err == nil, err
+}
+
+func (d *jsonldDecoder) newBlankNode() Term {
+	d.bnodes++
+	return generatedBlankNode(d.bnodes)
+}
+
+// with returns a copy of the context with a local context applied
+func (c *jsonldContext) with(local interface{}) (*jsonldContext, error) {
+	next := &jsonldContext{base: c.base, vocab: c.vocab, language: c.language, terms: make(map[string]jsonldTerm)}
+	for key, term := range c.terms {
+		next.terms[key] = term
+	}
+
+	items, ok := local.([]interface{})
+	if !ok {
+		items = []interface{}{local}
+	}
+
+	for _, item := range items {
+		switch definition := item.(type) {
+		case nil:
+			next = &jsonldContext{base: c.base, terms: make(map[string]jsonldTerm)}
+		case string:
+			return nil, fmt.Errorf("json-ld: remote context %q is not supported", definition)
+		case map[string]interface{}:
+			if err := next.define(definition); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("json-ld: invalid @context")
+		}
+	}
+
+	return next, nil
+}
+
+func (c *jsonldContext) define(definition map[string]interface{}) error {
+	if base, ok := definition["@base"].(string); ok {
+		parsed, err := url.Parse(base)
+		if err != nil {
+			return fmt.Errorf("json-ld: invalid @base %q", base)
+		}
+		c.base = parsed
+	}
+	if vocab, ok := definition["@vocab"].(string); ok {
+		c.vocab = vocab
+	}
+	if language, ok := definition["@language"].(string); ok {
+		c.language = language
+	}
+
+	// Terms may refer to each other, so expand them once every raw definition is known
+	raw := make(map[string]jsonldTerm)
+	for key, value := range definition {
+		if strings.HasPrefix(key, "@") {
+			continue
+		}
+
+		switch v := value.(type) {
+		case nil:
+			delete(c.terms, key)
+		case string:
+			raw[key] = jsonldTerm{id: v}
+		case map[string]interface{}:
+			if _, ok := v["@reverse"]; ok {
+				return fmt.Errorf("json-ld: @reverse is not supported for %q", key)
+			}
+
+			// A definition without an @id maps the term to itself, to be expanded by prefix or @vocab
+			term := jsonldTerm{id: key}
+			if id, ok := v["@id"].(string); ok {
+				term.id = id
+			}
+			term.typ, _ = v["@type"].(string)
+			term.container, _ = v["@container"].(string)
+			if language, ok := v["@language"]; ok {
+				l, _ := language.(string)
+				term.language = &l
+			}
+			raw[key] = term
+		default:
+			return fmt.Errorf("json-ld: invalid definition for %q", key)
+		}
+	}
+
+	for key, term := range raw {
+		c.terms[key] = term
+	}
+
+	for key, term := range raw {
+		term.id = c.expand(term.id, true)
+		if term.typ != "" && term.typ != "@id" && term.typ != "@vocab" {
+			term.typ = c.expand(term.typ, true)
+		}
+		c.terms[key] = term
+	}
+
+	return nil
+}
+
+// expand turns a term, compact IRI or relative IRI into an absolute IRI
+func (c *jsonldContext) expand(value string, vocab bool) string {
+	return c.expandDepth(value, vocab, 0)
+}
+
+// maxExpansionDepth stops terms that are defined in terms of each other from recursing forever
+const maxExpansionDepth = 8
+
+func (c *jsonldContext) expandDepth(value string, vocab bool, depth int) string {
+	if strings.HasPrefix(value, "@") || depth > maxExpansionDepth {
+		return value
+	}
+
+	if term, ok := c.terms[value]; ok && vocab && term.id != value {
+		return c.expandDepth(term.id, true, depth+1)
+	}
+
+	if index := strings.Index(value, ":"); index > 0 {
+		prefix, suffix := value[:index], value[index+1:]
+		if prefix == "_" || strings.HasPrefix(suffix, "//") {
+			return value
+		}
+		if term, ok := c.terms[prefix]; ok && term.id != prefix {
+			return c.expandDepth(term.id, true, depth+1) + suffix
+		}
+		return value
+	}
+
+	if vocab {
+		if c.vocab != "" {
+			return c.vocab + value
+		}
+		return ""
+	}
+
+	if c.base != nil {
+		if ref, err := url.Parse(value); err == nil {
+			return c.base.ResolveReference(ref).String()
+		}
+	}
+
+	return value
+}
+
+// resource expands a document-relative identifier, which may be a blank node
+func (c *jsonldContext) resource(value string) Term {
+	if strings.HasPrefix(value, "_:") {
+		return labelledBlankNode(strings.TrimPrefix(value, "_:"))
+	}
+
+	return NewIRI(c.expand(value, false))
+}
+
+// vocabResource expands a vocabulary-relative identifier such as a type
+func (c *jsonldContext) vocabResource(value string) Term {
+	if strings.HasPrefix(value, "_:") {
+		return labelledBlankNode(strings.TrimPrefix(value, "_:"))
+	}
+
+	if expanded := c.expand(value, true); expanded != "" {
+		return NewIRI(expanded)
+	}
+
+	return NewIRI(c.expand(value, false))
+}
+
+func hasProperties(object map[string]interface{}) bool {
+	for key := range object {
+		if key != "@context" && key != "@graph" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isList reports whether a value is already a list object
+func isList(value interface{}) bool {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, ok = object["@list"]
+	return ok
+}
+
+// members expands a property value into the values it holds, opening arrays and @set objects
+func members(value interface{}) []interface{} {
+	var items []interface{}
+	for _, item := range asArray(value) {
+		if object, ok := item.(map[string]interface{}); ok {
+			if set, ok := object["@set"]; ok {
+				items = append(items, asArray(set)...)
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func asArray(value interface{}) []interface{} {
+	if value == nil {
+		return nil
+	}
+	if array, ok := value.([]interface{}); ok {
+		return array
+	}
+
+	return []interface{}{value}
+}
@@ -1,98 +1,299 @@
 package processor
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"strings"
+	"github.com/ukparliament/gromnative/ext/types/graph"
 	"github.com/wallix/triplestore"
 	"log"
 )
 
-type Triple struct {
-	Subject   string `json:"subject"`
-	Predicate string `json:"predicate"`
-	Object    string `json:"object"`
-}
+// BlankNodeType is the type used to request blank node subjects, which carry no rdf:type of their own
+const BlankNodeType = "blank_node"
+
+const rdfType = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+
+// HydraNext links a page of a paged collection to the page that follows it
+const HydraNext = "http://www.w3.org/ns/hydra/core#next"
+
+// Triple is the generated graph.Triple, so processed statements match the protobuf schema
+type Triple = graph.Triple
 
 type ProcessorInput struct {
 	Body []byte
+	// Reader, when given, is decoded as it is read in place of Body
+	Reader io.Reader
+	// ContentType is the Content-Type header of the response, used to pick a decoder
+	ContentType string
+	// Documents, when given, are merged into a single graph in place of Body, dropping duplicate triples
+	Documents []Document
+	// Types, when given, limits the output to subjects of these types and the subjects reachable from them
+	Types []string
+	// EdgeNaming picks how edge keys are derived from predicates, and Prefixes maps prefixes to namespaces for Curie
+	EdgeNaming EdgeNaming
+	Prefixes   map[string]string
+	// MaxTriples, when given, stops decoding with a TooManyTriplesError once more triples than this are found
+	MaxTriples int64
+	// NextDocument, when given, is called once each document is decoded with the object of the first HydraNext
+	// triple in it, if any, and returns another document to merge or nil when there are no more
+	NextDocument func(hydraNext string) (*Document, error)
 }
 
 type ProcessorOutput struct {
 	StatementsBySubject map[string][]Triple
 	EdgesBySubject      map[string]map[string][]string
-	Error               string
+	// SubjectsByType holds, for each requested type in order, the subjects of that type
+	SubjectsByType [][]string
+	Error          string
+	// ErrorCode is set for failures callers may want to handle, such as TooManyTriples
+	ErrorCode string
+	// Results holds a SPARQL result set in place of a graph, for bodies picked out by ResultsDecoderFor
+	Results *Results
+}
+
+// TooManyTriples is the error code reported when a body holds more than ProcessorInput.MaxTriples triples
+const TooManyTriples = "too_many_triples"
+
+// TooManyTriplesError is returned when a body holds more than ProcessorInput.MaxTriples triples
+type TooManyTriplesError struct {
+	Max int64
+}
+
+func (e *TooManyTriplesError) Error() string {
+	return fmt.Sprintf("Found more than the limit of %v triples", e.Max)
+}
+
+// DocumentError is an error decoding one of several documents being merged, Index counting from zero
+type DocumentError struct {
+	Index int
+	Err   error
+}
+
+func (e *DocumentError) Error() string {
+	return fmt.Sprintf("document %v: %v", e.Index+1, e.Err)
+}
+
+// Document is a response body to be merged with others
+type Document struct {
+	Body        []byte
+	ContentType string
+	// Reader, when given, is decoded as it is read in place of Body
+	Reader io.Reader
+}
+
+// reader returns the document's content along with the first bytes, used to sniff its syntax
+func (d Document) reader() (io.Reader, []byte) {
+	if d.Reader == nil {
+		return bytes.NewReader(d.Body), d.Body
+	}
+
+	buffered := bufio.NewReaderSize(d.Reader, 4096)
+	head, _ := buffered.Peek(512)
+
+	return buffered, head
 }
 
 func NewTriple(t triplestore.Triple) Triple {
-	object := ""
+	return newTripleFromStatement(newStatement(t))
+}
 
-	bnode, isBnode := t.Object().Bnode()
-	literalObj, isLiteral := t.Object().Literal()
-	resource, _ := t.Object().Resource()
+// Process decodes and groups the input, using the processed graph cache when it is enabled
+func Process(input *ProcessorInput) (*ProcessorOutput, error) {
+	return ProcessContext(context.Background(), input)
+}
 
-	if isBnode {
-		object = "_:" + bnode
-	} else if isLiteral {
-		var literal string
+// ProcessContext is Process, stopping decoding with ctx.Err() once ctx is done
+func ProcessContext(ctx context.Context, input *ProcessorInput) (*ProcessorOutput, error) {
+	// Documents fetched by NextDocument are not known up front, so cannot be cached
+	cache := processedCache()
+	if cache == nil || input.NextDocument != nil {
+		return process(ctx, input)
+	}
 
-		if literalObj.Lang() != "" {
-			literal = "\"" + literalObj.Value() + "\"@" + literalObj.Lang()
-		} else {
-			literal = "\"" + literalObj.Value() + "\"^^<" + string(literalObj.Type()) + ">"
-		}
+	// The cache is keyed by the whole body, so readers have to be read up front
+	input, err := buffer(input)
+	if err != nil {
+		return &ProcessorOutput{Error: err.Error()}, err
+	}
 
-		object = literal
-	} else {
-		object = "<" + resource + ">"
+	key := cacheKey(input)
+	if output, ok := cache.get(key); ok {
+		log.Println("Found processed graph in cache")
+		return output, nil
 	}
 
-	return Triple{
-		Subject:   t.Subject(),
-		Predicate: t.Predicate(),
-		Object:    object,
+	output, err := process(ctx, input)
+	if err == nil {
+		cache.add(key, output)
 	}
+
+	return output, err
 }
 
-func Process(input *ProcessorInput) (*ProcessorOutput, error) {
+// buffer returns a copy of input with any readers read into bodies
+func buffer(input *ProcessorInput) (*ProcessorInput, error) {
+	buffered := *input
+
+	if input.Reader != nil {
+		body, err := ioutil.ReadAll(input.Reader)
+		if err != nil {
+			return nil, err
+		}
+		buffered.Body, buffered.Reader = body, nil
+	}
+
+	buffered.Documents = make([]Document, len(input.Documents))
+	for i, document := range input.Documents {
+		if document.Reader != nil {
+			body, err := ioutil.ReadAll(document.Reader)
+			if err != nil {
+				return nil, err
+			}
+			document.Body, document.Reader = body, nil
+		}
+		buffered.Documents[i] = document
+	}
+
+	return &buffered, nil
+}
+
+func process(ctx context.Context, input *ProcessorInput) (*ProcessorOutput, error) {
 	output := ProcessorOutput{}
 
 	// Used to group all statements under a shared subject
 	statementsBySubject := make(map[string][]Triple)
 	// Used to show how one object, through a predicate, links to one or more object
 	edgesBySubject := make(map[string]map[string][]string)
+	// Used to keep subjects in the order they were first seen
+	var subjects []string
+	// Used to look up the rdf:type values of each subject
+	typesBySubject := make(map[string][]string)
 
-	log.Println("Decoding")
-	dec := triplestore.NewDatasetDecoder(triplestore.NewLenientNTDecoder, bytes.NewReader(input.Body))
-	tris, err := dec.Decode()
+	edgeName, err := edgeNamer(input.EdgeNaming, input.Prefixes)
 	if err != nil {
-		log.Printf("Error decoding: %v\n", err)
 		output.Error = err.Error()
 		return &output, err
 	}
-	log.Printf("Decoded %v triples", len(tris))
 
-	for i := 0; i < len(tris); i++ {
-		triple := tris[i]
+	// Copied as NextDocument may add to it
+	documents := append([]Document(nil), input.Documents...)
+	merge := len(documents) > 0 || input.NextDocument != nil
+	if len(documents) == 0 {
+		document := Document{Body: input.Body, ContentType: input.ContentType, Reader: input.Reader}
+		reader, head := document.reader()
+
+		// SPARQL SELECT and ASK queries answer with a result set rather than a graph
+		if decode := ResultsDecoderFor(input.ContentType, head); decode != nil && !merge {
+			return processResults(decode, reader)
+		}
+
+		// Keep reading from where the head was peeked at
+		if document.Reader != nil {
+			document.Reader = reader
+		}
+		documents = []Document{document}
+	}
+
+	// Used to drop identical triples when merging
+	seen := make(map[[3]string]bool)
+
+	log.Println("Decoding")
+	count := 0
+	for i := 0; i < len(documents); i++ {
+		reader, head := documents[i].reader()
+		hydraNext := ""
 
-		subject := triple.Subject()
-		predicate := triple.Predicate()
-		object := triple.Object()
+		// An empty body, such as that of a 204 No Content response, is an empty graph in any syntax
+		decode := DecoderFor(documents[i].ContentType, head)
+		if len(head) == 0 {
+			decode = decodeNothing
+		}
 
-		statementsBySubject[subject] = append(statementsBySubject[subject], NewTriple(triple))
+		err = decode(reader, func(statement Statement) error {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
 
-		// decide if this is an edge
-		objectResource, _ := object.Resource()
-		if objectResource != "" && predicate != "http://www.w3.org/1999/02/22-rdf-syntax-ns#type" {
-			if edgesBySubject[subject] == nil {
-				edgesBySubject[subject] = make(map[string][]string)
+			// Blank node labels are only unique within a document
+			if merge {
+				statement.Subject = scopeBlankNode(statement.Subject, i)
+				statement.Object = scopeBlankNode(statement.Object, i)
 			}
 
-			predicateSlice := strings.Split(predicate, "/")
-			predicateObject := predicateSlice[len(predicateSlice)-1]
+			triple := newTripleFromStatement(statement)
+			if merge {
+				key := [3]string{triple.Subject, triple.Predicate, statement.Object.String()}
+				if seen[key] {
+					return nil
+				}
+				seen[key] = true
+			}
 
-			edgesBySubject[subject][predicateObject] = append(edgesBySubject[subject][predicateObject], objectResource)
+			count++
+			if input.MaxTriples > 0 && int64(count) > input.MaxTriples {
+				return &TooManyTriplesError{Max: input.MaxTriples}
+			}
+
+			subject := statement.Subject.Key()
+			predicate := statement.Predicate
+			object := statement.Object
+
+			if predicate == HydraNext && object.Kind == IRI && hydraNext == "" {
+				hydraNext = object.Value
+			}
+
+			if statementsBySubject[subject] == nil {
+				subjects = append(subjects, subject)
+			}
+			statementsBySubject[subject] = append(statementsBySubject[subject], triple)
+
+			// decide if this is an edge
+			if object.Kind == IRI && predicate == rdfType {
+				typesBySubject[subject] = append(typesBySubject[subject], object.Value)
+			}
+
+			if object.Kind == IRI && object.Value != "" && predicate != rdfType {
+				if edgesBySubject[subject] == nil {
+					edgesBySubject[subject] = make(map[string][]string)
+				}
+
+				predicateObject := edgeName(predicate)
+
+				edgesBySubject[subject][predicateObject] = append(edgesBySubject[subject][predicateObject], object.Value)
+			}
+
+			return nil
+		})
+		if err != nil {
+			log.Printf("Error decoding: %v\n", err)
+			if _, ok := err.(*TooManyTriplesError); ok {
+				output.ErrorCode = TooManyTriples
+			} else if merge {
+				err = &DocumentError{Index: i, Err: err}
+			}
+			output.Error = err.Error()
+			return &output, err
+		}
+
+		if input.NextDocument != nil {
+			next, err := input.NextDocument(hydraNext)
+			if err != nil {
+				output.Error = err.Error()
+				return &output, err
+			}
+			if next != nil {
+				documents = append(documents, *next)
+			}
 		}
 	}
+	log.Printf("Decoded %v triples", count)
 
 	log.Printf("Found %v subjects\n", len(statementsBySubject))
 
@@ -100,5 +301,112 @@ func Process(input *ProcessorInput) (*ProcessorOutput, error) {
 	output.StatementsBySubject = statementsBySubject
 	output.EdgesBySubject = edgesBySubject
 
+	if len(input.Types) > 0 {
+		filterByType(&output, input.Types, subjects, typesBySubject)
+		log.Printf("Kept %v subjects after filtering\n", len(output.StatementsBySubject))
+	}
+
+	return &output, nil
+}
+
+// processResults decodes a result set, leaving the graph empty
+func processResults(decode ResultsDecoder, reader io.Reader) (*ProcessorOutput, error) {
+	output := ProcessorOutput{StatementsBySubject: make(map[string][]Triple), EdgesBySubject: make(map[string]map[string][]string)}
+
+	log.Println("Decoding results")
+	results, err := decode(reader)
+	if err != nil {
+		log.Printf("Error decoding: %v\n", err)
+		output.Error = err.Error()
+		return &output, err
+	}
+	log.Printf("Decoded %v rows", len(results.Rows))
+
+	output.Results = results
+
 	return &output, nil
 }
+
+// decodeNothing is the Decoder for empty bodies
+func decodeNothing(r io.Reader, emit func(Statement) error) error {
+	return nil
+}
+
+// scopeBlankNode relabels a blank node so it cannot collide with one from another document
+func scopeBlankNode(term Term, document int) Term {
+	if term.Kind != BlankNode {
+		return term
+	}
+
+	return NewBlankNode(fmt.Sprintf("d%v_%v", document+1, term.Value))
+}
+
+// filterByType groups subjects under the requested types, then drops any subject
+// that cannot be reached from one of those groups by following edges.
+func filterByType(output *ProcessorOutput, types []string, subjects []string, typesBySubject map[string][]string) {
+	typeIndexes := make(map[string][]int)
+	for i, t := range types {
+		typeIndexes[t] = append(typeIndexes[t], i)
+	}
+
+	subjectsByType := make([][]string, len(types))
+	for i := range subjectsByType {
+		subjectsByType[i] = []string{}
+	}
+
+	var queue []string
+
+	for _, subject := range subjects {
+		subjectTypes := typesBySubject[subject]
+		if strings.HasPrefix(subject, "_:") {
+			subjectTypes = []string{BlankNodeType}
+		}
+
+		matched := false
+		for _, t := range subjectTypes {
+			for _, index := range typeIndexes[t] {
+				subjectsByType[index] = append(subjectsByType[index], subject)
+				matched = true
+			}
+		}
+
+		if matched {
+			queue = append(queue, subject)
+		}
+	}
+
+	// Walk the edges out from the matched subjects
+	reachable := make(map[string]bool)
+	for len(queue) > 0 {
+		subject := queue[0]
+		queue = queue[1:]
+
+		if reachable[subject] {
+			continue
+		}
+		reachable[subject] = true
+
+		for _, objects := range output.EdgesBySubject[subject] {
+			for _, object := range objects {
+				if !reachable[object] {
+					queue = append(queue, object)
+				}
+			}
+		}
+	}
+
+	statementsBySubject := make(map[string][]Triple)
+	edgesBySubject := make(map[string]map[string][]string)
+	for subject := range reachable {
+		if statements, ok := output.StatementsBySubject[subject]; ok {
+			statementsBySubject[subject] = statements
+		}
+		if edges, ok := output.EdgesBySubject[subject]; ok {
+			edgesBySubject[subject] = edges
+		}
+	}
+
+	output.StatementsBySubject = statementsBySubject
+	output.EdgesBySubject = edgesBySubject
+	output.SubjectsByType = subjectsByType
+}
@@ -0,0 +1,359 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// DecodeRDFXML reads RDF/XML, covering node and property elements, property attributes and the
+// Resource, Literal and Collection parse types
+func DecodeRDFXML(r io.Reader, emit func(Statement) error) error {
+	d := &rdfxmlDecoder{dec: xml.NewDecoder(r), emit: emit}
+
+	for {
+		token, err := d.dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("rdf/xml: %v", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		scope := rdfxmlScope{}.with(start)
+		if isRDF(start.Name, "RDF") {
+			err = d.nodeElements(scope)
+		} else {
+			_, err = d.nodeElement(start, scope)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type rdfxmlDecoder struct {
+	dec    *xml.Decoder
+	bnodes int
+	emit   func(Statement) error
+}
+
+// rdfxmlScope carries the inherited xml:base and xml:lang values
+type rdfxmlScope struct {
+	base     *url.URL
+	language string
+}
+
+func (s rdfxmlScope) with(start xml.StartElement) rdfxmlScope {
+	for _, attr := range start.Attr {
+		if !isXML(attr.Name) {
+			continue
+		}
+
+		switch attr.Name.Local {
+		case "base":
+			if base, err := url.Parse(attr.Value); err == nil {
+				s.base = base
+			}
+		case "lang":
+			s.language = attr.Value
+		}
+	}
+
+	return s
+}
+
+func (s rdfxmlScope) resolve(iri string) string {
+	if s.base == nil {
+		return iri
+	}
+
+	ref, err := url.Parse(iri)
+	if err != nil {
+		return iri
+	}
+
+	return s.base.ResolveReference(ref).String()
+}
+
+// nodeElements reads node elements until the enclosing element ends
+func (d *rdfxmlDecoder) nodeElements(scope rdfxmlScope) error {
+	for {
+		token, err := d.dec.Token()
+		if err != nil {
+			return fmt.Errorf("rdf/xml: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if _, err := d.nodeElement(t, scope); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// nodeElement emits the statements for a node element, returning its subject
+func (d *rdfxmlDecoder) nodeElement(start xml.StartElement, scope rdfxmlScope) (Term, error) {
+	scope = scope.with(start)
+
+	subject := Term{}
+	for _, attr := range start.Attr {
+		switch {
+		case isRDF(attr.Name, "about"):
+			subject = NewIRI(scope.resolve(attr.Value))
+		case isRDF(attr.Name, "ID"):
+			subject = NewIRI(scope.resolve("#" + attr.Value))
+		case isRDF(attr.Name, "nodeID"):
+			subject = labelledBlankNode(attr.Value)
+		}
+	}
+	if subject.Value == "" {
+		subject = d.newBlankNode()
+	}
+
+	if !isRDF(start.Name, "Description") {
+		if err := d.emit(Statement{Subject: subject, Predicate: rdfType, Object: NewIRI(start.Name.Space + start.Name.Local)}); err != nil {
+			return Term{}, err
+		}
+	}
+
+	if err := d.propertyAttributes(subject, start, scope); err != nil {
+		return Term{}, err
+	}
+
+	return subject, d.propertyElements(subject, scope)
+}
+
+// propertyElements reads property elements for subject until the enclosing element ends
+func (d *rdfxmlDecoder) propertyElements(subject Term, scope rdfxmlScope) error {
+	li := 0
+
+	for {
+		token, err := d.dec.Token()
+		if err != nil {
+			return fmt.Errorf("rdf/xml: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if err := d.propertyElement(subject, t, scope, &li); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+func (d *rdfxmlDecoder) propertyElement(subject Term, start xml.StartElement, scope rdfxmlScope, li *int) error {
+	scope = scope.with(start)
+
+	predicate := start.Name.Space + start.Name.Local
+	if isRDF(start.Name, "li") {
+		*li++
+		predicate = rdfNamespace + "_" + strconv.Itoa(*li)
+	}
+
+	var resource, nodeID, datatype, parseType string
+	hasPropertyAttributes := false
+	for _, attr := range start.Attr {
+		switch {
+		case isRDF(attr.Name, "resource"):
+			resource = attr.Value
+		case isRDF(attr.Name, "nodeID"):
+			nodeID = attr.Value
+		case isRDF(attr.Name, "datatype"):
+			datatype = attr.Value
+		case isRDF(attr.Name, "parseType"):
+			parseType = attr.Value
+		case isRDF(attr.Name, "type") || isPropertyAttribute(attr.Name):
+			hasPropertyAttributes = true
+		}
+	}
+
+	switch parseType {
+	case "Resource":
+		object := d.newBlankNode()
+		if err := d.emit(Statement{Subject: subject, Predicate: predicate, Object: object}); err != nil {
+			return err
+		}
+
+		return d.propertyElements(object, scope)
+	case "Literal":
+		text, err := d.innerXML()
+		if err != nil {
+			return err
+		}
+
+		return d.emit(Statement{Subject: subject, Predicate: predicate, Object: NewLiteral(text, rdfNamespace+"XMLLiteral", "")})
+	case "Collection":
+		var items []Term
+		for {
+			token, err := d.dec.Token()
+			if err != nil {
+				return fmt.Errorf("rdf/xml: %v", err)
+			}
+
+			if t, ok := token.(xml.StartElement); ok {
+				item, err := d.nodeElement(t, scope)
+				if err != nil {
+					return err
+				}
+				items = append(items, item)
+			}
+			if _, ok := token.(xml.EndElement); ok {
+				break
+			}
+		}
+
+		head, err := emitList(items, d.newBlankNode, d.emit)
+		if err != nil {
+			return err
+		}
+
+		return d.emit(Statement{Subject: subject, Predicate: predicate, Object: head})
+	}
+
+	if resource != "" || nodeID != "" || hasPropertyAttributes {
+		// An empty property element pointing at (or describing) another node
+		object := d.newBlankNode()
+		if resource != "" {
+			object = NewIRI(scope.resolve(resource))
+		} else if nodeID != "" {
+			object = labelledBlankNode(nodeID)
+		}
+
+		if err := d.emit(Statement{Subject: subject, Predicate: predicate, Object: object}); err != nil {
+			return err
+		}
+		if err := d.propertyAttributes(object, start, scope); err != nil {
+			return err
+		}
+
+		return d.dec.Skip()
+	}
+
+	var text strings.Builder
+	for {
+		token, err := d.dec.Token()
+		if err != nil {
+			return fmt.Errorf("rdf/xml: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.StartElement:
+			object, err := d.nodeElement(t, scope)
+			if err != nil {
+				return err
+			}
+			if err := d.emit(Statement{Subject: subject, Predicate: predicate, Object: object}); err != nil {
+				return err
+			}
+
+			return d.dec.Skip()
+		case xml.EndElement:
+			if datatype != "" {
+				return d.emit(Statement{Subject: subject, Predicate: predicate, Object: NewLiteral(text.String(), scope.resolve(datatype), "")})
+			}
+
+			return d.emit(Statement{Subject: subject, Predicate: predicate, Object: NewLiteral(text.String(), "", scope.language)})
+		}
+	}
+}
+
+// propertyAttributes emits the statements expressed as attributes on an element
+func (d *rdfxmlDecoder) propertyAttributes(subject Term, start xml.StartElement, scope rdfxmlScope) error {
+	for _, attr := range start.Attr {
+		var object Term
+		switch {
+		case isRDF(attr.Name, "type"):
+			object = NewIRI(scope.resolve(attr.Value))
+		case isPropertyAttribute(attr.Name):
+			object = NewLiteral(attr.Value, "", scope.language)
+		default:
+			continue
+		}
+
+		if err := d.emit(Statement{Subject: subject, Predicate: attr.Name.Space + attr.Name.Local, Object: object}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// innerXML re-serialises the content of the current element, consuming its end tag
+func (d *rdfxmlDecoder) innerXML() (string, error) {
+	var buffer bytes.Buffer
+	encoder := xml.NewEncoder(&buffer)
+
+	depth := 0
+	for {
+		token, err := d.dec.Token()
+		if err != nil {
+			return "", fmt.Errorf("rdf/xml: %v", err)
+		}
+
+		switch token.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			if depth == 0 {
+				if err := encoder.Flush(); err != nil {
+					return "", err
+				}
+				return buffer.String(), nil
+			}
+			depth--
+		}
+
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return "", fmt.Errorf("rdf/xml: %v", err)
+		}
+	}
+}
+
+func (d *rdfxmlDecoder) newBlankNode() Term {
+	d.bnodes++
+	return generatedBlankNode(d.bnodes)
+}
+
+func isRDF(name xml.Name, local string) bool {
+	return name.Space == rdfNamespace && name.Local == local
+}
+
+func isXML(name xml.Name) bool {
+	return name.Space == "xml" || name.Space == xmlNamespace
+}
+
+// isPropertyAttribute reports whether an attribute states a property rather than RDF/XML syntax
+func isPropertyAttribute(name xml.Name) bool {
+	if isXML(name) || name.Space == "xmlns" || (name.Space == "" && name.Local == "xmlns") || name.Space == "" {
+		return false
+	}
+
+	if name.Space == rdfNamespace {
+		switch name.Local {
+		case "about", "ID", "nodeID", "resource", "datatype", "parseType", "type", "li", "RDF", "Description", "aboutEach", "aboutEachPrefix", "bagID":
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,179 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	"io"
+	"mime"
+	"strings"
+)
+
+// Results is the generated graph.Results, a SPARQL SELECT result set or the answer to an ASK query
+type Results = graph.Results
+
+// ResultsDecoder reads a SPARQL SELECT result set, or the answer to an ASK query, from r
+type ResultsDecoder func(r io.Reader) (*Results, error)
+
+var resultsDecoders = map[string]ResultsDecoder{
+	"application/sparql-results+json": DecodeResultsJSON,
+	"application/sparql-results+xml":  DecodeResultsXML,
+}
+
+// ResultsDecoderFor picks a results decoder from a Content-Type header, sniffing the body when the header is
+// missing or unknown. It returns nil for bodies that hold a graph.
+func ResultsDecoderFor(contentType string, body []byte) ResultsDecoder {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		mediaType = strings.ToLower(mediaType)
+		if decoder, ok := resultsDecoders[mediaType]; ok {
+			return decoder
+		}
+
+		decodersMutex.RLock()
+		_, isGraph := decoders[mediaType]
+		decodersMutex.RUnlock()
+
+		if isGraph {
+			return nil
+		}
+	}
+
+	head := body
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	head = bytes.TrimLeft(head, " \t\r\n\ufeff")
+
+	switch {
+	case len(head) > 0 && head[0] == '{' && bytes.Contains(head, []byte(`"head"`)) && !bytes.Contains(head, []byte(`"@context"`)):
+		return DecodeResultsJSON
+	case bytes.Contains(head, []byte("<sparql")):
+		return DecodeResultsXML
+	}
+
+	return nil
+}
+
+// DecodeResultsJSON reads the SPARQL 1.1 Query Results JSON format
+func DecodeResultsJSON(r io.Reader) (*Results, error) {
+	var document struct {
+		Head struct {
+			Vars []string `json:"vars"`
+		} `json:"head"`
+		Results *struct {
+			Bindings []map[string]struct {
+				Type     string `json:"type"`
+				Value    string `json:"value"`
+				Datatype string `json:"datatype"`
+				Language string `json:"xml:lang"`
+			} `json:"bindings"`
+		} `json:"results"`
+		Boolean *bool `json:"boolean"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("sparql-results: %v", err)
+	}
+
+	if document.Boolean != nil {
+		return &Results{Ask: true, Boolean: *document.Boolean}, nil
+	}
+	if document.Results == nil {
+		return nil, fmt.Errorf("sparql-results: missing results or boolean")
+	}
+
+	results := &Results{Variables: document.Head.Vars, Rows: make([]*graph.Results_Row, len(document.Results.Bindings))}
+	for i, bindings := range document.Results.Bindings {
+		row := &graph.Results_Row{Bindings: make(map[string]*graph.Term, len(bindings))}
+		for variable, binding := range bindings {
+			term, err := resultsTerm(binding.Type, binding.Value, binding.Datatype, binding.Language)
+			if err != nil {
+				return nil, err
+			}
+			row.Bindings[variable] = term.Proto()
+		}
+		results.Rows[i] = row
+	}
+
+	return results, nil
+}
+
+// DecodeResultsXML reads the SPARQL Query Results XML format
+func DecodeResultsXML(r io.Reader) (*Results, error) {
+	type literal struct {
+		Value    string     `xml:",chardata"`
+		Datatype string     `xml:"datatype,attr"`
+		Attrs    []xml.Attr `xml:",any,attr"`
+	}
+
+	var document struct {
+		Variables []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"head>variable"`
+		Results []struct {
+			Bindings []struct {
+				Name    string   `xml:"name,attr"`
+				URI     *string  `xml:"uri"`
+				BNode   *string  `xml:"bnode"`
+				Literal *literal `xml:"literal"`
+			} `xml:"binding"`
+		} `xml:"results>result"`
+		Boolean *bool `xml:"boolean"`
+	}
+
+	if err := xml.NewDecoder(r).Decode(&document); err != nil {
+		return nil, fmt.Errorf("sparql-results: %v", err)
+	}
+
+	if document.Boolean != nil {
+		return &Results{Ask: true, Boolean: *document.Boolean}, nil
+	}
+
+	results := &Results{Rows: make([]*graph.Results_Row, len(document.Results))}
+	for _, variable := range document.Variables {
+		results.Variables = append(results.Variables, variable.Name)
+	}
+
+	for i, result := range document.Results {
+		row := &graph.Results_Row{Bindings: make(map[string]*graph.Term, len(result.Bindings))}
+		for _, binding := range result.Bindings {
+			var term Term
+			switch {
+			case binding.URI != nil:
+				term = NewIRI(*binding.URI)
+			case binding.BNode != nil:
+				term = NewBlankNode(*binding.BNode)
+			case binding.Literal != nil:
+				language := ""
+				for _, attr := range binding.Literal.Attrs {
+					if isXML(attr.Name) && attr.Name.Local == "lang" {
+						language = attr.Value
+					}
+				}
+				term = NewLiteral(binding.Literal.Value, binding.Literal.Datatype, language)
+			default:
+				return nil, fmt.Errorf("sparql-results: binding %v has no value", binding.Name)
+			}
+			row.Bindings[binding.Name] = term.Proto()
+		}
+		results.Rows[i] = row
+	}
+
+	return results, nil
+}
+
+// resultsTerm builds a term from a JSON binding, typed-literal being the type used before SPARQL 1.1
+func resultsTerm(kind string, value string, datatype string, language string) (Term, error) {
+	switch kind {
+	case "uri":
+		return NewIRI(value), nil
+	case "bnode":
+		return NewBlankNode(value), nil
+	case "literal", "typed-literal":
+		return NewLiteral(value, datatype, language), nil
+	}
+
+	return Term{}, fmt.Errorf("sparql-results: unknown term type %q", kind)
+}
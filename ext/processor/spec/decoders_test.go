@@ -0,0 +1,612 @@
+package spec
+
+import (
+  "bytes"
+  . "github.com/onsi/ginkgo"
+  . "github.com/onsi/gomega"
+  "github.com/ukparliament/gromnative/ext/processor"
+  "github.com/ukparliament/gromnative/ext/types/graph"
+  "io"
+  "strings"
+)
+
+var _ = Describe("Decoders", func() {
+  person := "https://id.parliament.uk/43RHonMf"
+
+  expectPerson := func(res *processor.ProcessorOutput, err error) {
+    Expect(err).NotTo(HaveOccurred())
+    Expect(res.StatementsBySubject[person]).To(ConsistOf(
+      processor.Triple{
+        Subject: person,
+        Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#type",
+        Object: processor.NewIRI("https://id.parliament.uk/schema/Person").Proto(),
+      },
+      processor.Triple{
+        Subject: person,
+        Predicate: "https://id.parliament.uk/schema/personGivenName",
+        Object: processor.NewLiteral("Diane", "", "").Proto(),
+      },
+      processor.Triple{
+        Subject: person,
+        Predicate: "https://id.parliament.uk/schema/personHasGenderIdentity",
+        Object: processor.NewIRI("https://id.parliament.uk/SPRKaz3b").Proto(),
+      },
+    ))
+    Expect(res.EdgesBySubject[person]["personHasGenderIdentity"]).To(Equal([]string{"https://id.parliament.uk/SPRKaz3b"}))
+  }
+
+  decode := func(decoder func(io.Reader, func(processor.Statement) error) error, body string) []processor.Statement {
+    statements := []processor.Statement{}
+    err := decoder(strings.NewReader(body), func(statement processor.Statement) error {
+      statements = append(statements, statement)
+      return nil
+    })
+    Expect(err).NotTo(HaveOccurred())
+
+    return statements
+  }
+
+  // subjectsByName maps the object of each http://example.com/q statement to its subject
+  subjectsByName := func(statements []processor.Statement) map[string]processor.Term {
+    subjects := map[string]processor.Term{}
+    for _, statement := range statements {
+      if statement.Predicate == "http://example.com/q" {
+        subjects[statement.Object.Value] = statement.Subject
+      }
+    }
+
+    return subjects
+  }
+
+  Describe("N-Triples", func() {
+    It("decodes from a reader", func() {
+      body := `<https://id.parliament.uk/43RHonMf> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .
+# a comment
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .
+
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personHasGenderIdentity> <https://id.parliament.uk/SPRKaz3b> .
+`
+
+      expectPerson(processor.Process(&processor.ProcessorInput{Reader: strings.NewReader(body)}))
+    })
+
+    It("returns an error for a graph label", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" <https://id.parliament.uk/graph> .`), ContentType: "application/n-triples"})
+
+      Expect(err).To(MatchError(HavePrefix("lenient parsing: line 1: missing '.' in")))
+    })
+  })
+
+  Describe("N-Quads", func() {
+    It("decodes the statements and drops the graph", func() {
+      body := []byte(`<https://id.parliament.uk/43RHonMf> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> <https://id.parliament.uk/graph> .
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personHasGenderIdentity> <https://id.parliament.uk/SPRKaz3b> _:g1 .
+`)
+
+      expectPerson(processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/n-quads"}))
+    })
+
+    It("returns an error for an invalid line", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`"Diane" <https://id.parliament.uk/schema/personGivenName> "Diane" .`), ContentType: "application/n-quads"})
+
+      Expect(err).To(MatchError(`n-quads: line 1: invalid subject in "Diane" <https://id.parliament.uk/schema/personGivenName> "Diane" .`))
+    })
+  })
+
+  Describe("Turtle", func() {
+    It("decodes prefixes, predicate lists and the a keyword", func() {
+      body := []byte(`@prefix schema: <https://id.parliament.uk/schema/> .
+@base <https://id.parliament.uk/> .
+
+# Diane Abbott
+<43RHonMf> a schema:Person ;
+  schema:personGivenName "Diane" ;
+  schema:personHasGenderIdentity <SPRKaz3b> .
+`)
+
+      expectPerson(processor.Process(&processor.ProcessorInput{Body: body, ContentType: "text/turtle; charset=utf-8"}))
+    })
+
+    It("decodes literals, blank nodes and collections", func() {
+      body := []byte(`PREFIX ex: <http://example.com/>
+ex:a ex:count 12, -1.5, 1e3 ;
+  ex:flag true ;
+  ex:label "hello"@en, """multi
+line""", 'single'^^ex:type ;
+  ex:knows [ ex:name "Anon" ] ;
+  ex:list ( ex:b ex:c ) .
+`)
+
+      res, err := processor.Process(&processor.ProcessorInput{Body: body, ContentType: "text/turtle"})
+      Expect(err).NotTo(HaveOccurred())
+
+      objects := []*graph.Term{}
+      for _, triple := range res.StatementsBySubject["http://example.com/a"] {
+        objects = append(objects, triple.Object)
+      }
+
+      Expect(objects).To(ContainElement(processor.NewLiteral("12", "http://www.w3.org/2001/XMLSchema#integer", "").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("-1.5", "http://www.w3.org/2001/XMLSchema#decimal", "").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("1e3", "http://www.w3.org/2001/XMLSchema#double", "").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("true", "http://www.w3.org/2001/XMLSchema#boolean", "").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("hello", "", "en").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("multi\nline", "", "").Proto()))
+      Expect(objects).To(ContainElement(processor.NewLiteral("single", "http://example.com/type", "").Proto()))
+      Expect(res.StatementsBySubject).To(HaveLen(4))
+    })
+
+    It("returns an error for an undefined prefix", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte("ex:a ex:b ex:c ."), ContentType: "text/turtle"})
+
+      Expect(err).To(MatchError(`turtle: line 1: undefined prefix "ex"`))
+    })
+
+    It("keeps anonymous blank nodes apart from labelled ones", func() {
+      subjects := subjectsByName(decode(processor.DecodeTurtle, `<http://example.com/s> <http://example.com/p> [ <http://example.com/q> "anon" ] .
+_:genid1 <http://example.com/q> "labelled" .
+`))
+
+      Expect(subjects).To(HaveLen(2))
+      Expect(subjects["anon"]).NotTo(Equal(subjects["labelled"]))
+    })
+  })
+
+  Describe("JSON-LD", func() {
+    It("decodes a document with a context", func() {
+      body := []byte(`{
+  "@context": {
+    "@vocab": "https://id.parliament.uk/schema/",
+    "@base": "https://id.parliament.uk/",
+    "personHasGenderIdentity": { "@type": "@id" }
+  },
+  "@id": "43RHonMf",
+  "@type": "Person",
+  "personGivenName": "Diane",
+  "personHasGenderIdentity": "SPRKaz3b"
+}`)
+
+      expectPerson(processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/ld+json"}))
+    })
+
+    It("decodes graphs, value objects and nested nodes", func() {
+      body := []byte(`{
+  "@context": { "ex": "http://example.com/" },
+  "@graph": [
+    {
+      "@id": "ex:a",
+      "ex:count": 12,
+      "ex:label": { "@value": "hello", "@language": "en" },
+      "ex:knows": { "ex:name": "Anon" }
+    }
+  ]
+}`)
+
+      res, err := processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/ld+json"})
+      Expect(err).NotTo(HaveOccurred())
+
+      Expect(res.StatementsBySubject).To(HaveLen(2))
+      Expect(res.StatementsBySubject["http://example.com/a"]).To(ContainElement(processor.Triple{
+        Subject: "http://example.com/a",
+        Predicate: "http://example.com/count",
+        Object: processor.NewLiteral("12", "http://www.w3.org/2001/XMLSchema#integer", "").Proto(),
+      }))
+      Expect(res.StatementsBySubject["http://example.com/a"]).To(ContainElement(processor.Triple{
+        Subject: "http://example.com/a",
+        Predicate: "http://example.com/label",
+        Object: processor.NewLiteral("hello", "", "en").Proto(),
+      }))
+    })
+
+    It("returns an error for a remote context", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{"@context": "http://schema.org/", "@id": "a"}`), ContentType: "application/ld+json"})
+
+      Expect(err).To(MatchError(`json-ld: remote context "http://schema.org/" is not supported`))
+    })
+
+    It("emits language maps in the same order every time", func() {
+      body := `{
+  "@context": { "ex": "http://example.com/", "label": { "@id": "ex:label", "@container": "@language" } },
+  "@id": "ex:a",
+  "label": { "fr": "bonjour", "en": "hello", "de": "hallo", "cy": "helo", "es": "hola" }
+}`
+
+      languages := func() []string {
+        var tags []string
+        err := processor.DecodeJSONLD(strings.NewReader(body), func(statement processor.Statement) error {
+          tags = append(tags, statement.Object.Language)
+          return nil
+        })
+        Expect(err).NotTo(HaveOccurred())
+
+        return tags
+      }
+
+      for i := 0; i < 10; i++ {
+        Expect(languages()).To(Equal([]string{"cy", "de", "en", "es", "fr"}))
+      }
+    })
+
+    It("skips values that only wrap a graph", func() {
+      body := []byte(`{
+  "@context": { "ex": "http://example.com/" },
+  "@id": "ex:a",
+  "ex:name": "A",
+  "ex:contains": { "@graph": [{ "@id": "ex:b", "ex:name": "B" }] }
+}`)
+
+      res, err := processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/ld+json"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject["http://example.com/a"]).To(HaveLen(1))
+      Expect(res.StatementsBySubject).To(HaveKey("http://example.com/b"))
+    })
+
+    It("returns an error for nested arrays", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{"@id": "http://example.com/a", "http://example.com/pairs": [[1, 2]]}`), ContentType: "application/ld+json"})
+
+      Expect(err).To(MatchError("json-ld: nested arrays are not supported"))
+    })
+
+    It("returns an error for reverse properties", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{"@id": "http://example.com/a", "@reverse": {"http://example.com/knows": {"@id": "http://example.com/b"}}}`), ContentType: "application/ld+json"})
+      Expect(err).To(MatchError("json-ld: @reverse is not supported"))
+
+      _, err = processor.Process(&processor.ProcessorInput{Body: []byte(`{"@context": {"knownBy": {"@reverse": "http://example.com/knows"}}, "@id": "http://example.com/a", "knownBy": "b"}`), ContentType: "application/ld+json"})
+      Expect(err).To(MatchError(`json-ld: @reverse is not supported for "knownBy"`))
+    })
+
+    It("expands every member of a @set", func() {
+      statements := decode(processor.DecodeJSONLD, `{
+  "@id": "http://example.com/a",
+  "http://example.com/p": { "@set": ["x", "y", { "@id": "http://example.com/b" }] }
+}`)
+
+      Expect(statements).To(ConsistOf(
+        processor.Statement{Subject: processor.NewIRI("http://example.com/a"), Predicate: "http://example.com/p", Object: processor.NewLiteral("x", "", "")},
+        processor.Statement{Subject: processor.NewIRI("http://example.com/a"), Predicate: "http://example.com/p", Object: processor.NewLiteral("y", "", "")},
+        processor.Statement{Subject: processor.NewIRI("http://example.com/a"), Predicate: "http://example.com/p", Object: processor.NewIRI("http://example.com/b")},
+      ))
+    })
+
+    It("keeps anonymous blank nodes apart from labelled ones", func() {
+      subjects := subjectsByName(decode(processor.DecodeJSONLD, `{
+  "@context": { "ex": "http://example.com/" },
+  "@graph": [
+    { "@id": "ex:s", "ex:p": { "ex:q": "anon" } },
+    { "@id": "_:genid1", "ex:q": "labelled" }
+  ]
+}`))
+
+      Expect(subjects).To(HaveLen(2))
+      Expect(subjects["anon"]).NotTo(Equal(subjects["labelled"]))
+    })
+
+    // Modelled on the basic cases of the JSON-LD test suite's expand manifest, checked against their RDF
+    Describe("expand cases", func() {
+      statement := func(subject, predicate string, object processor.Term) processor.Statement {
+        return processor.Statement{Subject: processor.NewIRI(subject), Predicate: predicate, Object: object}
+      }
+
+      It("expands terms, typed values, language values and arrays", func() {
+        statements := decode(processor.DecodeJSONLD, `{
+  "@context": {
+    "t1": "http://example.com/t1",
+    "t2": "http://example.com/t2",
+    "term1": "http://example.com/term1",
+    "term2": "http://example.com/term2",
+    "term3": "http://example.com/term3",
+    "term4": "http://example.com/term4",
+    "term5": "http://example.com/term5"
+  },
+  "@id": "http://example.com/id1",
+  "@type": "t1",
+  "term1": "v1",
+  "term2": { "@value": "v2", "@type": "t2" },
+  "term3": { "@value": "v3", "@language": "en" },
+  "term4": 4,
+  "term5": [50, 51]
+}`)
+
+        id := "http://example.com/id1"
+        Expect(statements).To(ConsistOf(
+          statement(id, "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", processor.NewIRI("http://example.com/t1")),
+          statement(id, "http://example.com/term1", processor.NewLiteral("v1", "", "")),
+          statement(id, "http://example.com/term2", processor.NewLiteral("v2", "http://example.com/t2", "")),
+          statement(id, "http://example.com/term3", processor.NewLiteral("v3", "", "en")),
+          statement(id, "http://example.com/term4", processor.NewLiteral("4", "http://www.w3.org/2001/XMLSchema#integer", "")),
+          statement(id, "http://example.com/term5", processor.NewLiteral("50", "http://www.w3.org/2001/XMLSchema#integer", "")),
+          statement(id, "http://example.com/term5", processor.NewLiteral("51", "http://www.w3.org/2001/XMLSchema#integer", "")),
+        ))
+      })
+
+      It("drops null and unmapped properties", func() {
+        statements := decode(processor.DecodeJSONLD, `{
+  "@id": "http://example.org/id",
+  "http://example.org/property": null,
+  "regularJson": { "nonJsonLd": "property", "deep": [{ "foo": "bar" }, { "bar": "foo" }] }
+}`)
+
+        Expect(statements).To(BeEmpty())
+      })
+
+      It("keeps empty lists and drops empty sets", func() {
+        statements := decode(processor.DecodeJSONLD, `{
+  "@context": {
+    "mylist1": { "@id": "http://example.com/mylist1", "@container": "@list" },
+    "mylist2": { "@id": "http://example.com/mylist2", "@container": "@list" },
+    "myset2": { "@id": "http://example.com/myset2", "@container": "@set" },
+    "myset3": { "@id": "http://example.com/myset3", "@container": "@set" }
+  },
+  "@id": "http://example.org/id",
+  "mylist1": { "@list": [] },
+  "mylist2": "one item",
+  "myset2": { "@set": [] },
+  "myset3": ["v1"],
+  "http://example.org/list1": { "@list": [null] },
+  "http://example.org/list2": { "@list": [{ "@value": null }] },
+  "http://example.org/set1": { "@set": [] },
+  "http://example.org/set2": { "@set": [null] },
+  "http://example.org/set3": { "@set": [{ "@value": null }] }
+}`)
+
+        id := "http://example.org/id"
+        rdfNil := processor.NewIRI("http://www.w3.org/1999/02/22-rdf-syntax-ns#nil")
+        item := processor.NewBlankNode("genid1")
+        Expect(statements).To(ConsistOf(
+          statement(id, "http://example.org/list1", rdfNil),
+          statement(id, "http://example.org/list2", rdfNil),
+          statement(id, "http://example.com/mylist1", rdfNil),
+          processor.Statement{Subject: item, Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#first", Object: processor.NewLiteral("one item", "", "")},
+          processor.Statement{Subject: item, Predicate: "http://www.w3.org/1999/02/22-rdf-syntax-ns#rest", Object: rdfNil},
+          statement(id, "http://example.com/mylist2", item),
+          statement(id, "http://example.com/myset3", processor.NewLiteral("v1", "", "")),
+        ))
+      })
+
+      It("expands the nodes of a @graph", func() {
+        statements := decode(processor.DecodeJSONLD, `{
+  "@context": {
+    "dc": "http://purl.org/dc/elements/1.1/",
+    "ex": "http://example.org/vocab#",
+    "ex:contains": { "@type": "@id" }
+  },
+  "@graph": [
+    {
+      "@id": "http://example.org/library",
+      "@type": "ex:Library",
+      "ex:contains": "http://example.org/library/the-republic"
+    },
+    {
+      "@id": "http://example.org/library/the-republic",
+      "@type": "ex:Book",
+      "dc:creator": "Plato",
+      "dc:title": "The Republic",
+      "ex:contains": "http://example.org/library/the-republic#introduction"
+    },
+    {
+      "@id": "http://example.org/library/the-republic#introduction",
+      "@type": "ex:Chapter",
+      "dc:description": "An introductory chapter on The Republic.",
+      "dc:title": "The Introduction"
+    }
+  ]
+}`)
+
+        library := "http://example.org/library"
+        book := "http://example.org/library/the-republic"
+        chapter := "http://example.org/library/the-republic#introduction"
+        Expect(statements).To(ConsistOf(
+          statement(library, "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", processor.NewIRI("http://example.org/vocab#Library")),
+          statement(library, "http://example.org/vocab#contains", processor.NewIRI(book)),
+          statement(book, "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", processor.NewIRI("http://example.org/vocab#Book")),
+          statement(book, "http://purl.org/dc/elements/1.1/creator", processor.NewLiteral("Plato", "", "")),
+          statement(book, "http://purl.org/dc/elements/1.1/title", processor.NewLiteral("The Republic", "", "")),
+          statement(book, "http://example.org/vocab#contains", processor.NewIRI(chapter)),
+          statement(chapter, "http://www.w3.org/1999/02/22-rdf-syntax-ns#type", processor.NewIRI("http://example.org/vocab#Chapter")),
+          statement(chapter, "http://purl.org/dc/elements/1.1/description", processor.NewLiteral("An introductory chapter on The Republic.", "", "")),
+          statement(chapter, "http://purl.org/dc/elements/1.1/title", processor.NewLiteral("The Introduction", "", "")),
+        ))
+      })
+
+      It("scopes nested contexts to the node that holds them", func() {
+        statements := decode(processor.DecodeJSONLD, `{
+  "@context": { "@vocab": "http://example.com/", "name": "http://xmlns.com/foaf/0.1/name" },
+  "@id": "http://example.org/a",
+  "name": "A",
+  "knows": {
+    "@context": { "@vocab": "http://example.net/", "@language": "en" },
+    "@id": "http://example.org/b",
+    "name": "B",
+    "nick": "bee"
+  },
+  "member": {
+    "@context": null,
+    "@id": "http://example.org/c",
+    "http://example.com/name": "C",
+    "nick": "dropped"
+  },
+  "nick": "ay"
+}`)
+
+        a := "http://example.org/a"
+        b := "http://example.org/b"
+        Expect(statements).To(ConsistOf(
+          statement(a, "http://xmlns.com/foaf/0.1/name", processor.NewLiteral("A", "", "")),
+          statement(a, "http://example.com/knows", processor.NewIRI(b)),
+          statement(b, "http://xmlns.com/foaf/0.1/name", processor.NewLiteral("B", "", "en")),
+          statement(b, "http://example.net/nick", processor.NewLiteral("bee", "", "en")),
+          statement(a, "http://example.com/member", processor.NewIRI("http://example.org/c")),
+          statement("http://example.org/c", "http://example.com/name", processor.NewLiteral("C", "", "")),
+          statement(a, "http://example.com/nick", processor.NewLiteral("ay", "", "")),
+        ))
+      })
+    })
+  })
+
+  Describe("RDF/XML", func() {
+    It("decodes typed node elements and property elements", func() {
+      body := []byte(`<?xml version="1.0"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:schema="https://id.parliament.uk/schema/" xml:base="https://id.parliament.uk/">
+  <schema:Person rdf:about="43RHonMf">
+    <schema:personGivenName>Diane</schema:personGivenName>
+    <schema:personHasGenderIdentity rdf:resource="SPRKaz3b"/>
+  </schema:Person>
+</rdf:RDF>`)
+
+      expectPerson(processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/rdf+xml"}))
+    })
+
+    It("decodes nested nodes, datatypes and languages", func() {
+      body := []byte(`<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.com/">
+  <rdf:Description rdf:about="http://example.com/a" ex:name="A">
+    <ex:count rdf:datatype="http://www.w3.org/2001/XMLSchema#integer">12</ex:count>
+    <ex:label xml:lang="en">hello</ex:label>
+    <ex:knows>
+      <rdf:Description ex:name="Anon"/>
+    </ex:knows>
+  </rdf:Description>
+</rdf:RDF>`)
+
+      res, err := processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/rdf+xml"})
+      Expect(err).NotTo(HaveOccurred())
+
+      objects := []*graph.Term{}
+      for _, triple := range res.StatementsBySubject["http://example.com/a"] {
+        objects = append(objects, triple.Object)
+      }
+
+      Expect(objects).To(ConsistOf(
+        processor.NewLiteral("A", "", "").Proto(),
+        processor.NewLiteral("12", "http://www.w3.org/2001/XMLSchema#integer", "").Proto(),
+        processor.NewLiteral("hello", "", "en").Proto(),
+        processor.NewBlankNode("genid1").Proto(),
+      ))
+    })
+
+    It("keeps anonymous blank nodes apart from labelled ones", func() {
+      subjects := subjectsByName(decode(processor.DecodeRDFXML, `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns:ex="http://example.com/">
+  <rdf:Description rdf:about="http://example.com/s">
+    <ex:p>
+      <rdf:Description ex:q="anon"/>
+    </ex:p>
+  </rdf:Description>
+  <rdf:Description rdf:nodeID="genid1" ex:q="labelled"/>
+</rdf:RDF>`))
+
+      Expect(subjects).To(HaveLen(2))
+      Expect(subjects["anon"]).NotTo(Equal(subjects["labelled"]))
+    })
+  })
+
+  Describe("DecoderFor", func() {
+    It("sniffs JSON-LD when there is no content type", func() {
+      res, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{"@id": "http://example.com/a", "http://example.com/name": "A"}`)})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject).To(HaveKey("http://example.com/a"))
+    })
+
+    It("sniffs the syntax of a reader", func() {
+      res, err := processor.Process(&processor.ProcessorInput{Reader: strings.NewReader("@prefix ex: <http://example.com/> .\nex:a ex:name \"A\" .")})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject).To(HaveKey("http://example.com/a"))
+    })
+
+    It("sniffs Turtle when the content type is unknown", func() {
+      res, err := processor.Process(&processor.ProcessorInput{Body: []byte("@prefix ex: <http://example.com/> .\nex:a ex:name \"A\" ."), ContentType: "text/plain"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject).To(HaveKey("http://example.com/a"))
+    })
+
+    It("uses a registered decoder", func() {
+      processor.RegisterDecoder("application/x-test", func(r io.Reader, emit func(processor.Statement) error) error {
+        return emit(processor.Statement{
+          Subject: processor.NewIRI("http://example.com/a"),
+          Predicate: "http://example.com/name",
+          Object: processor.NewLiteral("A", "", ""),
+        })
+      })
+
+      res, err := processor.Process(&processor.ProcessorInput{Body: []byte("anything"), ContentType: "application/x-test"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject["http://example.com/a"]).To(Equal([]processor.Triple{{
+        Subject: "http://example.com/a",
+        Predicate: "http://example.com/name",
+        Object: processor.NewLiteral("A", "", "").Proto(),
+      }}))
+    })
+  })
+
+  Describe("SPARQL results", func() {
+    expectRows := func(res *processor.ProcessorOutput, err error) {
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.StatementsBySubject).To(BeEmpty())
+      Expect(res.Results.Variables).To(Equal([]string{"person", "name"}))
+      Expect(res.Results.Rows).To(HaveLen(2))
+      Expect(res.Results.Rows[0].Bindings).To(Equal(map[string]*graph.Term{
+        "person": processor.NewIRI("https://id.parliament.uk/43RHonMf").Proto(),
+        "name": processor.NewLiteral("Diane", "", "en").Proto(),
+      }))
+      Expect(res.Results.Rows[1].Bindings).To(Equal(map[string]*graph.Term{
+        "person": processor.NewBlankNode("b0").Proto(),
+        "name": processor.NewLiteral("12", "http://www.w3.org/2001/XMLSchema#integer", "").Proto(),
+      }))
+    }
+
+    It("decodes a JSON result set", func() {
+      body := []byte(`{
+  "head": { "vars": ["person", "name"] },
+  "results": { "bindings": [
+    { "person": { "type": "uri", "value": "https://id.parliament.uk/43RHonMf" }, "name": { "type": "literal", "value": "Diane", "xml:lang": "en" } },
+    { "person": { "type": "bnode", "value": "b0" }, "name": { "type": "typed-literal", "value": "12", "datatype": "http://www.w3.org/2001/XMLSchema#integer" } }
+  ] }
+}`)
+
+      expectRows(processor.Process(&processor.ProcessorInput{Body: body, ContentType: "application/sparql-results+json"}))
+    })
+
+    It("decodes an XML result set", func() {
+      body := []byte(`<?xml version="1.0"?>
+<sparql xmlns="http://www.w3.org/2005/sparql-results#">
+  <head><variable name="person"/><variable name="name"/></head>
+  <results>
+    <result>
+      <binding name="person"><uri>https://id.parliament.uk/43RHonMf</uri></binding>
+      <binding name="name"><literal xml:lang="en">Diane</literal></binding>
+    </result>
+    <result>
+      <binding name="person"><bnode>b0</bnode></binding>
+      <binding name="name"><literal datatype="http://www.w3.org/2001/XMLSchema#integer">12</literal></binding>
+    </result>
+  </results>
+</sparql>`)
+
+      expectRows(processor.Process(&processor.ProcessorInput{Reader: bytes.NewReader(body), ContentType: "application/sparql-results+xml"}))
+    })
+
+    It("decodes the answer to an ASK query", func() {
+      res, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{ "head": {}, "boolean": false }`), ContentType: "application/sparql-results+json"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Results.Ask).To(BeTrue())
+      Expect(res.Results.Boolean).To(BeFalse())
+    })
+
+    It("sniffs a result set when the content type is unknown", func() {
+      res, err := processor.Process(&processor.ProcessorInput{Body: []byte(`<sparql xmlns="http://www.w3.org/2005/sparql-results#"><head/><boolean>true</boolean></sparql>`), ContentType: "application/xml"})
+
+      Expect(err).NotTo(HaveOccurred())
+      Expect(res.Results.Boolean).To(BeTrue())
+    })
+
+    It("returns an error for an unknown term type", func() {
+      _, err := processor.Process(&processor.ProcessorInput{Body: []byte(`{ "head": { "vars": ["a"] }, "results": { "bindings": [{ "a": { "type": "triple", "value": "" } }] } }`), ContentType: "application/sparql-results+json"})
+
+      Expect(err).To(MatchError(`sparql-results: unknown term type "triple"`))
+    })
+  })
+})
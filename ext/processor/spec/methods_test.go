@@ -1,11 +1,15 @@
 package spec
 
 import (
+  "context"
   . "github.com/onsi/ginkgo"
   . "github.com/onsi/gomega"
+  "github.com/golang/protobuf/proto"
   "github.com/ukparliament/gromnative/ext/processor"
+  "github.com/ukparliament/gromnative/ext/types/graph"
   "github.com/wallix/triplestore"
   "io/ioutil"
+  "strings"
 )
 
 var _ = Describe("Processor", func() {
@@ -15,7 +19,7 @@ var _ = Describe("Processor", func() {
         expected := processor.Triple{
           Subject: "https://id.parliament.uk/12345678",
           Predicate: "https://id.parliament.uk/shema/PredicateName",
-          Object: "_:node39387803",
+          Object: &graph.Term{Kind: graph.Term_BLANK_NODE, Value: "node39387803"},
         }
 
         triple := triplestore.SubjPredBnode("https://id.parliament.uk/12345678", "https://id.parliament.uk/shema/PredicateName", "node39387803")
@@ -31,7 +35,7 @@ var _ = Describe("Processor", func() {
         expected := processor.Triple{
           Subject: "https://id.parliament.uk/12345678",
           Predicate: "https://id.parliament.uk/shema/PredicateName",
-          Object:  "\"12\"^^<xsd:integer>",
+          Object: &graph.Term{Kind: graph.Term_LITERAL, Value: "12", Datatype: "http://www.w3.org/2001/XMLSchema#integer"},
         }
 
         triple, err := triplestore.SubjPredLit("https://id.parliament.uk/12345678", "https://id.parliament.uk/shema/PredicateName", 12)
@@ -48,7 +52,7 @@ var _ = Describe("Processor", func() {
         expected := processor.Triple{
           Subject: "https://id.parliament.uk/12345678",
           Predicate: "https://id.parliament.uk/shema/PredicateName",
-          Object:  "<https://id.parliament.uk/23456789>",
+          Object:  processor.NewIRI("https://id.parliament.uk/23456789").Proto(),
         }
 
         triple := triplestore.SubjPredRes("https://id.parliament.uk/12345678", "https://id.parliament.uk/shema/PredicateName", "https://id.parliament.uk/23456789")
@@ -85,6 +89,239 @@ var _ = Describe("Processor", func() {
         Expect(res).To(Equal(expected))
         Expect(err).NotTo(HaveOccurred())
       })
+
+      It("returns empty objects whatever the content type", func() {
+        res, err := processor.Process(&processor.ProcessorInput{ Reader: strings.NewReader(""), ContentType: "application/ld+json" })
+
+        Expect(res.StatementsBySubject).To(BeEmpty())
+        Expect(err).NotTo(HaveOccurred())
+      })
+    })
+
+    Context("with types", func() {
+      body := []byte(`<https://id.parliament.uk/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .
+<https://id.parliament.uk/1> <https://id.parliament.uk/schema/partyMemberHasPartyMembership> <https://id.parliament.uk/2> .
+<https://id.parliament.uk/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/PartyMembership> .
+<https://id.parliament.uk/2> <https://id.parliament.uk/schema/partyMembershipHasParty> <https://id.parliament.uk/3> .
+<https://id.parliament.uk/3> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Party> .
+<https://id.parliament.uk/4> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Party> .
+_:node1 <https://id.parliament.uk/schema/count> "12" .
+`)
+
+      It("groups subjects by the requested types", func() {
+        res, err := processor.Process(&processor.ProcessorInput{
+          Body: body,
+          Types: []string{"https://id.parliament.uk/schema/Person", processor.BlankNodeType, "https://id.parliament.uk/schema/House"},
+        })
+
+        Expect(res.SubjectsByType).To(Equal([][]string{
+          {"https://id.parliament.uk/1"},
+          {"_:node1"},
+          {},
+        }))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("only keeps subjects reachable from the requested types", func() {
+        res, err := processor.Process(&processor.ProcessorInput{
+          Body: body,
+          Types: []string{"https://id.parliament.uk/schema/Person"},
+        })
+
+        Expect(res.StatementsBySubject).To(HaveLen(3))
+        Expect(res.StatementsBySubject).To(HaveKey("https://id.parliament.uk/3"))
+        Expect(res.StatementsBySubject).NotTo(HaveKey("https://id.parliament.uk/4"))
+        Expect(res.EdgesBySubject).To(HaveLen(2))
+        Expect(err).NotTo(HaveOccurred())
+      })
+    })
+
+    Context("with an edge naming", func() {
+      body := []byte(`<https://id.parliament.uk/1> <http://a.example.com/schema/name> <https://id.parliament.uk/2> .
+<https://id.parliament.uk/1> <http://b.example.com/vocab/name> <https://id.parliament.uk/3> .
+<https://id.parliament.uk/1> <http://www.w3.org/2000/01/rdf-schema#seeAlso> <https://id.parliament.uk/4> .
+`)
+
+      It("keys edges by the last segment by default", func() {
+        res, err := processor.Process(&processor.ProcessorInput{Body: body})
+
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]).To(Equal(map[string][]string{
+          "name": {"https://id.parliament.uk/2", "https://id.parliament.uk/3"},
+          "rdf-schema#seeAlso": {"https://id.parliament.uk/4"},
+        }))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("keys edges by local name", func() {
+        res, err := processor.Process(&processor.ProcessorInput{Body: body, EdgeNaming: processor.LocalName})
+
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]).To(HaveKey("seeAlso"))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("keys edges by CURIE, falling back to the full URI", func() {
+        res, err := processor.Process(&processor.ProcessorInput{
+          Body: body,
+          EdgeNaming: processor.Curie,
+          Prefixes: map[string]string{"a": "http://a.example.com/", "aschema": "http://a.example.com/schema/", "rdfs": "http://www.w3.org/2000/01/rdf-schema#"},
+        })
+
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]).To(Equal(map[string][]string{
+          "aschema:name": {"https://id.parliament.uk/2"},
+          "http://b.example.com/vocab/name": {"https://id.parliament.uk/3"},
+          "rdfs:seeAlso": {"https://id.parliament.uk/4"},
+        }))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("keys edges by full URI", func() {
+        res, err := processor.Process(&processor.ProcessorInput{Body: body, EdgeNaming: processor.FullURI})
+
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]).To(HaveLen(3))
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]).To(HaveKey("http://a.example.com/schema/name"))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("returns an error for an unknown naming", func() {
+        _, err := processor.Process(&processor.ProcessorInput{Body: body, EdgeNaming: "camel_case"})
+
+        Expect(err).To(MatchError(`unknown edge naming "camel_case"`))
+      })
+    })
+
+    Context("with documents", func() {
+      first := processor.Document{
+        Body: []byte(`<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .
+<https://id.parliament.uk/1> <https://id.parliament.uk/schema/knows> <https://id.parliament.uk/2> .
+_:b1 <https://id.parliament.uk/schema/count> "1" .
+`),
+      }
+      second := processor.Document{
+        Body: []byte(`@prefix schema: <https://id.parliament.uk/schema/> .
+<https://id.parliament.uk/1> schema:name "One" .
+<https://id.parliament.uk/2> schema:name "Two" .
+_:b1 schema:count "2" .
+`),
+        ContentType: "text/turtle",
+      }
+
+      It("merges the documents and drops duplicate triples", func() {
+        res, err := processor.Process(&processor.ProcessorInput{Documents: []processor.Document{first, second}})
+
+        Expect(res.StatementsBySubject["https://id.parliament.uk/1"]).To(HaveLen(2))
+        Expect(res.StatementsBySubject["https://id.parliament.uk/2"]).To(HaveLen(1))
+        Expect(res.EdgesBySubject["https://id.parliament.uk/1"]["knows"]).To(Equal([]string{"https://id.parliament.uk/2"}))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("keeps blank nodes from different documents apart", func() {
+        res, err := processor.Process(&processor.ProcessorInput{Documents: []processor.Document{first, second}})
+
+        Expect(res.StatementsBySubject).To(HaveKey("_:d1_b1"))
+        Expect(res.StatementsBySubject).To(HaveKey("_:d2_b1"))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("returns an error naming the document that failed", func() {
+        _, err := processor.Process(&processor.ProcessorInput{Documents: []processor.Document{first, {Body: []byte("ex:a ex:b ex:c ."), ContentType: "text/turtle"}}})
+
+        Expect(err).To(MatchError(`document 2: turtle: line 1: undefined prefix "ex"`))
+      })
+
+      It("asks for the next document with the hydra:next link of each one", func() {
+        page := processor.Document{Body: []byte(`<https://id.parliament.uk/1> <http://www.w3.org/ns/hydra/core#next> <https://id.parliament.uk/page/2> .`)}
+        var links []string
+
+        res, err := processor.Process(&processor.ProcessorInput{
+          Documents: []processor.Document{page},
+          NextDocument: func(hydraNext string) (*processor.Document, error) {
+            links = append(links, hydraNext)
+            if len(links) > 1 {
+              return nil, nil
+            }
+
+            return &second, nil
+          },
+        })
+
+        Expect(links).To(Equal([]string{"https://id.parliament.uk/page/2", ""}))
+        Expect(res.StatementsBySubject["https://id.parliament.uk/1"]).To(HaveLen(2))
+        Expect(err).NotTo(HaveOccurred())
+      })
+    })
+
+    Context("with the graph cache", func() {
+      body := []byte(`<https://id.parliament.uk/1> <https://id.parliament.uk/schema/name> "One" .`)
+      other := []byte(`<https://id.parliament.uk/2> <https://id.parliament.uk/schema/name> "Two" .`)
+
+      BeforeEach(func() {
+        processor.ConfigureCache(1024)
+      })
+
+      AfterEach(func() {
+        processor.ConfigureCache(0)
+      })
+
+      It("reuses the output for an identical body", func() {
+        first, _ := processor.Process(&processor.ProcessorInput{Body: body})
+        second, err := processor.Process(&processor.ProcessorInput{Body: body})
+
+        Expect(second).To(BeIdenticalTo(first))
+        stats := processor.CacheStatistics()
+        Expect(stats.Entries).To(Equal(1))
+        Expect(stats.Hits).To(Equal(int64(1)))
+        Expect(stats.Misses).To(Equal(int64(1)))
+        Expect(err).NotTo(HaveOccurred())
+      })
+
+      It("keys the cache by processing options", func() {
+        first, _ := processor.Process(&processor.ProcessorInput{Body: body})
+        second, _ := processor.Process(&processor.ProcessorInput{Body: body, EdgeNaming: processor.FullURI})
+
+        Expect(second).NotTo(BeIdenticalTo(first))
+        Expect(processor.CacheStatistics().Misses).To(Equal(int64(2)))
+      })
+
+      It("evicts the least recently used graph to stay under the ceiling", func() {
+        processor.Process(&processor.ProcessorInput{Body: body})
+        size := processor.CacheStatistics().Bytes
+        processor.ConfigureCache(size + size/2)
+
+        processor.Process(&processor.ProcessorInput{Body: body})
+        processor.Process(&processor.ProcessorInput{Body: other})
+
+        stats := processor.CacheStatistics()
+        Expect(stats.Entries).To(Equal(1))
+        Expect(stats.Evictions).To(Equal(int64(1)))
+        Expect(stats.Bytes).To(BeNumerically("<=", stats.MaxBytes))
+      })
+    })
+
+    Context("with a triple limit", func() {
+      It("stops decoding with a typed error once the limit is passed", func() {
+        fixture, _ := ioutil.ReadFile("../../../spec/fixtures/full.nt")
+
+        res, err := processor.Process(&processor.ProcessorInput{Body: fixture, MaxTriples: 10})
+
+        Expect(err).To(Equal(&processor.TooManyTriplesError{Max: 10}))
+        Expect(res.ErrorCode).To(Equal(processor.TooManyTriples))
+        Expect(res.Error).To(Equal("Found more than the limit of 10 triples"))
+      })
+    })
+
+    Context("with a cancelled context", func() {
+      It("stops decoding with the context's error", func() {
+        fixture, _ := ioutil.ReadFile("../../../spec/fixtures/full.nt")
+
+        ctx, cancel := context.WithCancel(context.Background())
+        cancel()
+
+        res, err := processor.ProcessContext(ctx, &processor.ProcessorInput{Body: fixture})
+
+        Expect(err).To(Equal(context.Canceled))
+        Expect(res.Error).To(Equal("context canceled"))
+        Expect(res.StatementsBySubject).To(BeNil())
+      })
     })
 
     Context("with full data", func() {
@@ -99,4 +336,25 @@ var _ = Describe("Processor", func() {
       })
     })
   })
+
+  Describe("Graph", func() {
+    body := []byte(`<https://id.parliament.uk/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .
+<https://id.parliament.uk/1> <https://id.parliament.uk/schema/partyMemberHasPartyMembership> <https://id.parliament.uk/2> .
+`)
+
+    It("copies the output into protobuf messages", func() {
+      res, _ := processor.Process(&processor.ProcessorInput{ Body: body, Types: []string{"https://id.parliament.uk/schema/Person"} })
+
+      data, err := proto.Marshal(res.Graph())
+      Expect(err).NotTo(HaveOccurred())
+
+      decoded := &graph.Graph{}
+      Expect(proto.Unmarshal(data, decoded)).To(Succeed())
+
+      Expect(decoded.StatementsBySubject["https://id.parliament.uk/1"].Triples).To(HaveLen(2))
+      Expect(decoded.StatementsBySubject["https://id.parliament.uk/1"].Triples[1].Object).To(Equal(processor.NewIRI("https://id.parliament.uk/2").Proto()))
+      Expect(decoded.EdgesBySubject["https://id.parliament.uk/1"].Edges["partyMemberHasPartyMembership"].Uris).To(Equal([]string{"https://id.parliament.uk/2"}))
+      Expect(decoded.SubjectsByType[0].Subjects).To(Equal([]string{"https://id.parliament.uk/1"}))
+    })
+  })
 })
\ No newline at end of file
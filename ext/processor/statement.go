@@ -0,0 +1,145 @@
+package processor
+
+import (
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	"github.com/wallix/triplestore"
+	"strings"
+)
+
+// TermKind says what sort of RDF term a Term holds
+type TermKind int
+
+const (
+	IRI TermKind = iota
+	BlankNode
+	Literal
+)
+
+// plainDatatype is the datatype triplestore gives untyped literals, used by every decoder so they render alike
+const plainDatatype = "xsd:string"
+
+const (
+	xsdNamespace = "http://www.w3.org/2001/XMLSchema#"
+	rdfNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+)
+
+// Term is a single RDF term as read by a Decoder
+type Term struct {
+	Kind     TermKind
+	Value    string
+	Datatype string
+	Language string
+}
+
+// Statement is a single triple as read by a Decoder, independent of the syntax it came from
+type Statement struct {
+	Subject   Term
+	Predicate string
+	Object    Term
+}
+
+func NewIRI(value string) Term {
+	return Term{Kind: IRI, Value: value}
+}
+
+func NewBlankNode(label string) Term {
+	return Term{Kind: BlankNode, Value: label}
+}
+
+func NewLiteral(value string, datatype string, language string) Term {
+	if language != "" {
+		datatype = ""
+	} else if datatype == "" {
+		datatype = plainDatatype
+	}
+
+	return Term{Kind: Literal, Value: value, Datatype: datatype, Language: language}
+}
+
+// Key renders a subject the way it is keyed in StatementsBySubject
+func (t Term) Key() string {
+	if t.Kind == BlankNode {
+		return "_:" + t.Value
+	}
+
+	return t.Value
+}
+
+// String renders the term as an N-Triples fragment
+func (t Term) String() string {
+	switch t.Kind {
+	case BlankNode:
+		return "_:" + t.Value
+	case Literal:
+		if t.Language != "" {
+			return "\"" + t.Value + "\"@" + t.Language
+		}
+
+		return "\"" + t.Value + "\"^^<" + t.Datatype + ">"
+	default:
+		return "<" + t.Value + ">"
+	}
+}
+
+// Proto converts the term to a graph.Term, expanding xsd: datatypes to full IRIs
+func (t Term) Proto() *graph.Term {
+	term := &graph.Term{Kind: t.Kind.Proto(), Value: t.Value, Language: t.Language, Datatype: t.Datatype}
+	if strings.HasPrefix(term.Datatype, "xsd:") {
+		term.Datatype = xsdNamespace + strings.TrimPrefix(term.Datatype, "xsd:")
+	}
+
+	return term
+}
+
+// Proto converts the kind to a graph.Term_Kind
+func (k TermKind) Proto() graph.Term_Kind {
+	switch k {
+	case BlankNode:
+		return graph.Term_BLANK_NODE
+	case Literal:
+		return graph.Term_LITERAL
+	default:
+		return graph.Term_IRI
+	}
+}
+
+func newStatement(t triplestore.Triple) Statement {
+	var subject Term
+	if strings.HasPrefix(t.Subject(), "_:") {
+		subject = NewBlankNode(strings.TrimPrefix(t.Subject(), "_:"))
+	} else {
+		subject = NewIRI(t.Subject())
+	}
+
+	var object Term
+
+	bnode, isBnode := t.Object().Bnode()
+	literalObj, isLiteral := t.Object().Literal()
+	resource, _ := t.Object().Resource()
+
+	if isBnode {
+		object = NewBlankNode(bnode)
+	} else if isLiteral {
+		object = Term{Kind: Literal, Value: literalObj.Value(), Language: literalObj.Lang()}
+		if literalObj.Lang() == "" {
+			object.Datatype = string(literalObj.Type())
+		}
+	} else {
+		object = NewIRI(resource)
+	}
+
+	return Statement{
+		Subject:   subject,
+		Predicate: t.Predicate(),
+		Object:    object,
+	}
+}
+
+func newTripleFromStatement(s Statement) Triple {
+	return Triple{
+		Subject:     s.Subject.Key(),
+		Predicate:   s.Predicate,
+		Object:      s.Object.Proto(),
+		SubjectKind: s.Subject.Kind.Proto(),
+	}
+}
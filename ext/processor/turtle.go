@@ -0,0 +1,613 @@
+package processor
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DecodeTurtle reads Turtle, including SPARQL-style PREFIX and BASE directives
+func DecodeTurtle(r io.Reader, emit func(Statement) error) error {
+	body, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	p := &turtleParser{
+		text:     string(body),
+		line:     1,
+		prefixes: make(map[string]string),
+		emit:     emit,
+	}
+
+	return p.parse()
+}
+
+type turtleParser struct {
+	text     string
+	pos      int
+	line     int
+	base     *url.URL
+	prefixes map[string]string
+	bnodes   int
+	emit     func(Statement) error
+}
+
+func (p *turtleParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("turtle: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *turtleParser) parse() error {
+	for {
+		p.skipSpace()
+		if p.done() {
+			return nil
+		}
+
+		if err := p.statement(); err != nil {
+			return err
+		}
+	}
+}
+
+func (p *turtleParser) statement() error {
+	switch {
+	case p.consume("@prefix"):
+		return p.prefixDirective(true)
+	case p.consume("@base"):
+		return p.baseDirective(true)
+	case p.consumeKeyword("PREFIX"):
+		return p.prefixDirective(false)
+	case p.consumeKeyword("BASE"):
+		return p.baseDirective(false)
+	}
+
+	var subject Term
+	var err error
+
+	if p.peek() == '[' {
+		// A blank node property list may stand alone as a statement
+		subject, err = p.blankNodePropertyList()
+		if err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		if p.peek() == '.' {
+			p.pos++
+			return nil
+		}
+	} else {
+		subject, err = p.subject()
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := p.predicateObjectList(subject); err != nil {
+		return err
+	}
+
+	return p.expect('.')
+}
+
+func (p *turtleParser) prefixDirective(terminated bool) error {
+	p.skipSpace()
+
+	start := p.pos
+	for !p.done() && p.peek() != ':' {
+		p.pos++
+	}
+	if p.done() {
+		return p.errorf("invalid prefix declaration")
+	}
+
+	prefix := strings.TrimSpace(p.text[start:p.pos])
+	p.pos++
+
+	p.skipSpace()
+	iri, err := p.iriRef()
+	if err != nil {
+		return err
+	}
+	p.prefixes[prefix] = iri
+
+	if terminated {
+		return p.expect('.')
+	}
+
+	return nil
+}
+
+func (p *turtleParser) baseDirective(terminated bool) error {
+	p.skipSpace()
+
+	iri, err := p.iriRef()
+	if err != nil {
+		return err
+	}
+
+	base, err := url.Parse(iri)
+	if err != nil {
+		return p.errorf("invalid base %q", iri)
+	}
+	p.base = base
+
+	if terminated {
+		return p.expect('.')
+	}
+
+	return nil
+}
+
+func (p *turtleParser) subject() (Term, error) {
+	p.skipSpace()
+
+	switch {
+	case p.peek() == '(':
+		return p.collection()
+	case strings.HasPrefix(p.text[p.pos:], "_:"):
+		return p.blankNodeLabel(), nil
+	}
+
+	iri, err := p.iri()
+	if err != nil {
+		return Term{}, err
+	}
+
+	return NewIRI(iri), nil
+}
+
+func (p *turtleParser) predicateObjectList(subject Term) error {
+	for {
+		p.skipSpace()
+
+		predicate, err := p.predicate()
+		if err != nil {
+			return err
+		}
+
+		if err := p.objectList(subject, predicate); err != nil {
+			return err
+		}
+
+		// Predicates are separated by one or more semicolons, and a trailing one is allowed
+		p.skipSpace()
+		if p.peek() != ';' {
+			return nil
+		}
+		for !p.done() && p.peek() == ';' {
+			p.pos++
+			p.skipSpace()
+		}
+
+		if p.done() || p.peek() == '.' || p.peek() == ']' {
+			return nil
+		}
+	}
+}
+
+func (p *turtleParser) predicate() (string, error) {
+	if p.peek() == 'a' && p.pos+1 < len(p.text) && isTurtleSpace(p.text[p.pos+1]) {
+		p.pos++
+		return rdfType, nil
+	}
+
+	return p.iri()
+}
+
+func (p *turtleParser) objectList(subject Term, predicate string) error {
+	for {
+		object, err := p.object()
+		if err != nil {
+			return err
+		}
+
+		if err := p.emit(Statement{Subject: subject, Predicate: predicate, Object: object}); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		if p.peek() != ',' {
+			return nil
+		}
+		p.pos++
+	}
+}
+
+func (p *turtleParser) object() (Term, error) {
+	p.skipSpace()
+	if p.done() {
+		return Term{}, p.errorf("unexpected end of input")
+	}
+
+	c := p.peek()
+	switch {
+	case c == '[':
+		return p.blankNodePropertyList()
+	case c == '(':
+		return p.collection()
+	case c == '"' || c == '\'':
+		return p.literal()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.numeric()
+	case strings.HasPrefix(p.text[p.pos:], "_:"):
+		return p.blankNodeLabel(), nil
+	case p.consumeWord("true"):
+		return NewLiteral("true", xsdNamespace+"boolean", ""), nil
+	case p.consumeWord("false"):
+		return NewLiteral("false", xsdNamespace+"boolean", ""), nil
+	}
+
+	iri, err := p.iri()
+	if err != nil {
+		return Term{}, err
+	}
+
+	return NewIRI(iri), nil
+}
+
+func (p *turtleParser) blankNodePropertyList() (Term, error) {
+	p.pos++
+	node := p.newBlankNode()
+
+	p.skipSpace()
+	if p.peek() == ']' {
+		p.pos++
+		return node, nil
+	}
+
+	if err := p.predicateObjectList(node); err != nil {
+		return Term{}, err
+	}
+
+	return node, p.expect(']')
+}
+
+func (p *turtleParser) collection() (Term, error) {
+	p.pos++
+
+	var items []Term
+	for {
+		p.skipSpace()
+		if p.done() {
+			return Term{}, p.errorf("unterminated collection")
+		}
+		if p.peek() == ')' {
+			p.pos++
+			break
+		}
+
+		item, err := p.object()
+		if err != nil {
+			return Term{}, err
+		}
+		items = append(items, item)
+	}
+
+	return emitList(items, p.newBlankNode, p.emit)
+}
+
+func (p *turtleParser) literal() (Term, error) {
+	quote := p.text[p.pos : p.pos+1]
+	long := strings.HasPrefix(p.text[p.pos:], strings.Repeat(quote, 3))
+
+	var value string
+	if long {
+		p.pos += 3
+		end := p.pos
+		for {
+			index := strings.Index(p.text[end:], strings.Repeat(quote, 3))
+			if index < 0 {
+				return Term{}, p.errorf("unterminated string")
+			}
+			end += index
+
+			if !isEscaped(p.text, end) {
+				break
+			}
+			end++
+		}
+
+		// Allow up to two extra quotes immediately before the closing delimiter
+		for end+3 < len(p.text) && p.text[end+3:end+4] == quote {
+			end++
+		}
+
+		value = p.text[p.pos:end]
+		p.line += strings.Count(value, "\n")
+		p.pos = end + 3
+	} else {
+		p.pos++
+		start := p.pos
+		for !p.done() && p.text[p.pos:p.pos+1] != quote {
+			if p.peek() == '\n' {
+				return Term{}, p.errorf("newline in string")
+			}
+			if p.peek() == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.done() {
+			return Term{}, p.errorf("unterminated string")
+		}
+
+		value = p.text[start:p.pos]
+		p.pos++
+	}
+
+	value, err := unescape(value)
+	if err != nil {
+		return Term{}, p.errorf("%v", err)
+	}
+
+	if !p.done() && p.peek() == '@' {
+		p.pos++
+		start := p.pos
+		for !p.done() && (isAlphaNumeric(p.peek()) || p.peek() == '-') {
+			p.pos++
+		}
+
+		return NewLiteral(value, "", p.text[start:p.pos]), nil
+	}
+
+	if strings.HasPrefix(p.text[p.pos:], "^^") {
+		p.pos += 2
+
+		datatype, err := p.iri()
+		if err != nil {
+			return Term{}, err
+		}
+
+		return NewLiteral(value, datatype, ""), nil
+	}
+
+	return NewLiteral(value, "", ""), nil
+}
+
+func (p *turtleParser) numeric() (Term, error) {
+	start := p.pos
+	if p.peek() == '+' || p.peek() == '-' {
+		p.pos++
+	}
+
+	datatype := "integer"
+loop:
+	for !p.done() {
+		c := p.peek()
+		switch {
+		case c >= '0' && c <= '9':
+		case c == '.' && datatype == "integer" && p.pos+1 < len(p.text) && p.text[p.pos+1] >= '0' && p.text[p.pos+1] <= '9':
+			datatype = "decimal"
+		case (c == 'e' || c == 'E') && datatype != "double":
+			datatype = "double"
+			if p.pos+1 < len(p.text) && (p.text[p.pos+1] == '+' || p.text[p.pos+1] == '-') {
+				p.pos++
+			}
+		default:
+			break loop
+		}
+		p.pos++
+	}
+
+	value := p.text[start:p.pos]
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return Term{}, p.errorf("invalid number %q", value)
+	}
+
+	return NewLiteral(value, xsdNamespace+datatype, ""), nil
+}
+
+// iri reads an IRI reference or prefixed name, returning the absolute IRI
+func (p *turtleParser) iri() (string, error) {
+	p.skipSpace()
+	if p.done() {
+		return "", p.errorf("unexpected end of input")
+	}
+
+	if p.peek() == '<' {
+		return p.iriRef()
+	}
+
+	start := p.pos
+	for !p.done() && (isNameChar(p.text, p.pos) || p.peek() == '-' || p.peek() == '.') {
+		_, size := utf8.DecodeRuneInString(p.text[p.pos:])
+		p.pos += size
+	}
+	if p.done() || p.peek() != ':' {
+		return "", p.errorf("expected IRI at %q", p.text[start:p.pos])
+	}
+
+	prefix := p.text[start:p.pos]
+	namespace, ok := p.prefixes[prefix]
+	if !ok {
+		return "", p.errorf("undefined prefix %q", prefix)
+	}
+	p.pos++
+
+	var local strings.Builder
+	for !p.done() {
+		c := p.peek()
+		switch {
+		case c == '\\' && p.pos+1 < len(p.text):
+			local.WriteByte(p.text[p.pos+1])
+			p.pos += 2
+		case c == '%' && p.pos+2 < len(p.text):
+			local.WriteString(p.text[p.pos : p.pos+3])
+			p.pos += 3
+		case c == '.':
+			// Dots are allowed inside local names but never at the end
+			if p.pos+1 < len(p.text) && (isNameChar(p.text, p.pos+1) || p.text[p.pos+1] == ':') {
+				local.WriteByte(c)
+				p.pos++
+				continue
+			}
+			return namespace + local.String(), nil
+		case c == ':' || c == '-' || isNameChar(p.text, p.pos):
+			_, size := utf8.DecodeRuneInString(p.text[p.pos:])
+			local.WriteString(p.text[p.pos : p.pos+size])
+			p.pos += size
+		default:
+			return namespace + local.String(), nil
+		}
+	}
+
+	return namespace + local.String(), nil
+}
+
+func (p *turtleParser) iriRef() (string, error) {
+	if p.done() || p.peek() != '<' {
+		return "", p.errorf("expected IRI")
+	}
+
+	end := strings.IndexByte(p.text[p.pos:], '>')
+	if end < 0 {
+		return "", p.errorf("unterminated IRI")
+	}
+
+	iri, err := unescape(p.text[p.pos+1 : p.pos+end])
+	if err != nil {
+		return "", p.errorf("%v", err)
+	}
+	p.pos += end + 1
+
+	return p.resolve(iri), nil
+}
+
+// resolve makes a relative IRI absolute against the current base
+func (p *turtleParser) resolve(iri string) string {
+	if p.base == nil {
+		return iri
+	}
+
+	ref, err := url.Parse(iri)
+	if err != nil || ref.IsAbs() {
+		return iri
+	}
+
+	return p.base.ResolveReference(ref).String()
+}
+
+func (p *turtleParser) blankNodeLabel() Term {
+	p.pos += 2
+	start := p.pos
+	for !p.done() && (isNameChar(p.text, p.pos) || p.peek() == '-' || (p.peek() == '.' && p.pos+1 < len(p.text) && isNameChar(p.text, p.pos+1))) {
+		_, size := utf8.DecodeRuneInString(p.text[p.pos:])
+		p.pos += size
+	}
+
+	return labelledBlankNode(p.text[start:p.pos])
+}
+
+func (p *turtleParser) newBlankNode() Term {
+	p.bnodes++
+	return generatedBlankNode(p.bnodes)
+}
+
+func (p *turtleParser) expect(c byte) error {
+	p.skipSpace()
+	if p.done() || p.peek() != c {
+		found := "end of input"
+		if !p.done() {
+			found = strconv.QuoteRune(rune(p.peek()))
+		}
+
+		return p.errorf("expected %q, found %s", c, found)
+	}
+	p.pos++
+
+	return nil
+}
+
+func (p *turtleParser) consume(s string) bool {
+	if strings.HasPrefix(p.text[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+
+	return false
+}
+
+// consumeKeyword matches a case-insensitive SPARQL-style directive
+func (p *turtleParser) consumeKeyword(keyword string) bool {
+	end := p.pos + len(keyword)
+	if end < len(p.text) && strings.EqualFold(p.text[p.pos:end], keyword) && isTurtleSpace(p.text[end]) {
+		p.pos = end
+		return true
+	}
+
+	return false
+}
+
+// consumeWord matches a bare word such as true or false, but not a prefixed name starting with one
+func (p *turtleParser) consumeWord(word string) bool {
+	end := p.pos + len(word)
+	if !strings.HasPrefix(p.text[p.pos:], word) {
+		return false
+	}
+	if end < len(p.text) && (isNameChar(p.text, end) || p.text[end] == ':') {
+		return false
+	}
+
+	p.pos = end
+	return true
+}
+
+func (p *turtleParser) skipSpace() {
+	for !p.done() {
+		switch c := p.peek(); {
+		case c == '\n':
+			p.line++
+			p.pos++
+		case isTurtleSpace(c):
+			p.pos++
+		case c == '#':
+			for !p.done() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *turtleParser) done() bool {
+	return p.pos >= len(p.text)
+}
+
+func (p *turtleParser) peek() byte {
+	if p.done() {
+		return 0
+	}
+
+	return p.text[p.pos]
+}
+
+func isTurtleSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isAlphaNumeric(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+func isNameChar(text string, pos int) bool {
+	r, _ := utf8.DecodeRuneInString(text[pos:])
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || r == 0xB7 || (r >= 0x300 && r <= 0x36F) || r == 0x203F || r == 0x2040
+}
+
+// isEscaped reports whether the character at pos is preceded by an odd number of backslashes
+func isEscaped(text string, pos int) bool {
+	count := 0
+	for i := pos - 1; i >= 0 && text[i] == '\\'; i-- {
+		count++
+	}
+
+	return count%2 == 1
+}
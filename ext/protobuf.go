@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	"github.com/ukparliament/gromnative/ext/processor"
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	"log"
+)
+
+// newGraphResponse copies a response into its protobuf form
+func newGraphResponse(response *Response) *graph.Response {
+	output := &processor.ProcessorOutput{
+		StatementsBySubject: response.StatementsBySubject,
+		EdgesBySubject:      response.EdgesBySubject,
+		SubjectsByType:      response.SubjectsByType,
+	}
+
+	graphResponse := &graph.Response{
+		Graph:      output.Graph(),
+		StatusCode: response.StatusCode,
+		Attempts:   response.Attempts,
+		Cache:      response.Cache,
+		Uri:        response.Uri,
+		Error:      response.Err,
+		ErrorCode:  response.ErrorCode,
+		Pages:      response.Pages,
+		Results:    response.Results,
+	}
+
+	if details := response.ErrorDetails; details != nil {
+		graphResponse.ErrorDetails = &graph.Error{
+			Kind:          details.Kind,
+			Message:       details.Message,
+			StatusCode:    details.StatusCode,
+			Uri:           details.Uri,
+			Retryable:     details.Retryable,
+			AttemptErrors: details.AttemptErrors,
+		}
+	}
+
+	return graphResponse
+}
+
+// respondProto is respond for the protobuf exports, encoding the response as a length prefixed graph.Response
+func respondProto(response Response, err error) []byte {
+	if err != nil {
+		response.Err = fmt.Sprintf("Error getting data: %v\n", err)
+		log.Println(response.Err)
+	}
+
+	data, err := proto.Marshal(newGraphResponse(&response))
+	if err != nil {
+		errorResponse := &Response{Err: fmt.Sprintf("Error marshalling data: %v\n", err)}
+		errorResponse.ErrorDetails = &Error{Kind: MarshalError, Message: err.Error()}
+		log.Println(errorResponse.Err)
+		data, _ = proto.Marshal(newGraphResponse(errorResponse))
+	}
+
+	return lengthPrefixed(data)
+}
+
+// lengthPrefixed prepends the length of data as a big-endian uint32, so callers can read binary data that may hold NUL bytes
+func lengthPrefixed(data []byte) []byte {
+	buffer := make([]byte, 4+len(data))
+	binary.BigEndian.PutUint32(buffer, uint32(len(data)))
+	copy(buffer[4:], data)
+
+	return buffer
+}
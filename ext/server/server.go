@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"github.com/ukparliament/gromnative/ext/net"
+	"github.com/ukparliament/gromnative/ext/processor"
+	. "github.com/ukparliament/gromnative/ext/types/net"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"log"
+)
+
+// Server implements the Net service using the client and caches shared by the net and processor packages.
+// Failures are reported in each output, as they are across the FFI boundary, rather than as gRPC errors.
+type Server struct{}
+
+// New returns a Server, configure the net and processor packages before serving it to change their defaults
+func New() *Server {
+	return &Server{}
+}
+
+func (s *Server) Get(ctx context.Context, input *GetInput) (*GetOutput, error) {
+	log.Printf("Requesting: %v\n", input.Uri)
+
+	output, err := net.GetContext(ctx, input)
+	if err != nil {
+		log.Printf("Error getting: %v\n", err)
+	}
+
+	return output, nil
+}
+
+func (s *Server) Do(ctx context.Context, input *Request) (*GetOutput, error) {
+	log.Printf("Sending %v: %v\n", input.Method, input.Uri)
+
+	output, err := net.DoContext(ctx, input)
+	if err != nil {
+		log.Printf("Error sending: %v\n", err)
+	}
+
+	return output, nil
+}
+
+func (s *Server) Process(ctx context.Context, input *ProcessInput) (*ProcessOutput, error) {
+	processorInput := newProcessorInput(input)
+	processorInput.Body = input.Body
+	processorInput.ContentType = input.ContentType
+
+	output, err := processor.ProcessContext(ctx, processorInput)
+	if err != nil {
+		log.Printf("Error processing: %v\n", err)
+	}
+
+	return newProcessOutput(output), nil
+}
+
+func (s *Server) GetAndProcess(ctx context.Context, input *GetAndProcessInput) (*GetAndProcessOutput, error) {
+	if input.Get == nil {
+		return nil, status.Error(codes.InvalidArgument, "get is required")
+	}
+
+	options := input.Process
+	if options == nil {
+		options = &ProcessInput{}
+	}
+
+	log.Printf("Requesting: %v\n", input.Get.Uri)
+
+	// Decode the body as it arrives rather than holding all of it in memory
+	var processed *processor.ProcessorOutput
+	output, err := net.StreamContext(ctx, input.Get, func(output *GetOutput, body io.Reader) error {
+		processorInput := newProcessorInput(options)
+		processorInput.Reader = body
+		processorInput.ContentType = output.ContentType
+		if processorInput.MaxTriples == 0 {
+			processorInput.MaxTriples = input.Get.MaxTriples
+		}
+
+		var err error
+		processed, err = processor.ProcessContext(ctx, processorInput)
+
+		return err
+	})
+	if err != nil {
+		log.Printf("Error getting and processing: %v\n", err)
+	}
+
+	result := &GetAndProcessOutput{Get: output}
+	if processed != nil {
+		result.Process = newProcessOutput(processed)
+	}
+
+	return result, nil
+}
+
+// newProcessorInput copies the processing options from input, leaving the body to the caller
+func newProcessorInput(input *ProcessInput) *processor.ProcessorInput {
+	return &processor.ProcessorInput{
+		Types:      input.Types,
+		EdgeNaming: processor.EdgeNaming(input.EdgeNaming),
+		Prefixes:   input.Prefixes,
+		MaxTriples: input.MaxTriples,
+	}
+}
+
+func newProcessOutput(output *processor.ProcessorOutput) *ProcessOutput {
+	graph := output.Graph()
+
+	return &ProcessOutput{
+		StatementsBySubject: graph.StatementsBySubject,
+		EdgesBySubject:      graph.EdgesBySubject,
+		SubjectsByType:      graph.SubjectsByType,
+		Error:               output.Error,
+		ErrorCode:           output.ErrorCode,
+		Results:             output.Results,
+	}
+}
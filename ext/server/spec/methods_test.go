@@ -0,0 +1,151 @@
+package spec
+
+import (
+	"context"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/ukparliament/gromnative/ext/server"
+	. "github.com/ukparliament/gromnative/ext/types/net"
+	"github.com/ukparliament/gromnative/ext/types/graph"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/jarcoal/httpmock.v1"
+	"io/ioutil"
+	stdnet "net"
+	"net/http"
+)
+
+var _ = Describe("Server", func() {
+	uri := "https://api.parliament.uk/query/person_by_id?person_id=43RHonMf"
+	body := `<https://id.parliament.uk/43RHonMf> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <https://id.parliament.uk/schema/Person> .
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/personGivenName> "Diane" .
+<https://id.parliament.uk/43RHonMf> <https://id.parliament.uk/schema/memberHasParliamentaryIncumbency> <https://id.parliament.uk/4Yxsxi5K> .
+`
+
+	var grpcServer *grpc.Server
+	var connection *grpc.ClientConn
+	var client NetClient
+
+	BeforeEach(func() {
+		listener, err := stdnet.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+
+		grpcServer = grpc.NewServer()
+		RegisterNetServer(grpcServer, server.New())
+		go grpcServer.Serve(listener)
+
+		connection, err = grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+		Expect(err).NotTo(HaveOccurred())
+
+		client = NewNetClient(connection)
+	})
+
+	AfterEach(func() {
+		connection.Close()
+		grpcServer.Stop()
+	})
+
+	Describe("Get", func() {
+		It("fetches the body", func() {
+			httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(200, body))
+
+			output, err := client.Get(context.Background(), &GetInput{Uri: uri})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.StatusCode).To(Equal(int32(200)))
+			Expect(output.Body).To(Equal([]byte(body)))
+		})
+
+		It("reports failures in the output", func() {
+			httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(404, "Not Found"))
+
+			output, err := client.Get(context.Background(), &GetInput{Uri: uri})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.StatusCode).To(Equal(int32(404)))
+			Expect(output.ErrorKind).To(Equal("http_status"))
+			Expect(output.Error).To(Equal("Received 404 status code from " + uri + ": Not Found"))
+		})
+	})
+
+	Describe("Do", func() {
+		It("sends the method and body", func() {
+			var received []byte
+			httpmock.RegisterResponder("PUT", uri, func(req *http.Request) (*http.Response, error) {
+				received, _ = ioutil.ReadAll(req.Body)
+
+				return httpmock.NewStringResponse(204, ""), nil
+			})
+
+			output, err := client.Do(context.Background(), &Request{Method: "PUT", Uri: uri, Body: []byte(body), ContentType: "application/n-triples"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.StatusCode).To(Equal(int32(204)))
+			Expect(output.Error).To(BeEmpty())
+			Expect(received).To(Equal([]byte(body)))
+		})
+	})
+
+	Describe("Process", func() {
+		It("groups the statements and edges", func() {
+			output, err := client.Process(context.Background(), &ProcessInput{Body: []byte(body)})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.StatementsBySubject["https://id.parliament.uk/43RHonMf"].Triples).To(HaveLen(3))
+			object := output.StatementsBySubject["https://id.parliament.uk/43RHonMf"].Triples[1].Object
+			Expect(object.Kind).To(Equal(graph.Term_LITERAL))
+			Expect(object.Value).To(Equal("Diane"))
+			Expect(object.Datatype).To(Equal("http://www.w3.org/2001/XMLSchema#string"))
+			Expect(output.EdgesBySubject["https://id.parliament.uk/43RHonMf"].Edges["memberHasParliamentaryIncumbency"].Uris).To(Equal([]string{"https://id.parliament.uk/4Yxsxi5K"}))
+		})
+
+		It("groups subjects by type", func() {
+			output, err := client.Process(context.Background(), &ProcessInput{Body: []byte(body), Types: []string{"https://id.parliament.uk/schema/Person"}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.SubjectsByType).To(HaveLen(1))
+			Expect(output.SubjectsByType[0].Subjects).To(Equal([]string{"https://id.parliament.uk/43RHonMf"}))
+		})
+
+		It("reports failures in the output", func() {
+			output, err := client.Process(context.Background(), &ProcessInput{Body: []byte(body), MaxTriples: 1})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.ErrorCode).To(Equal("too_many_triples"))
+			Expect(output.Error).To(Equal("Found more than the limit of 1 triples"))
+		})
+	})
+
+	Describe("GetAndProcess", func() {
+		It("fetches and processes the body", func() {
+			httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(200, body))
+
+			output, err := client.GetAndProcess(context.Background(), &GetAndProcessInput{
+				Get:     &GetInput{Uri: uri},
+				Process: &ProcessInput{EdgeNaming: "full_uri"},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.Get.StatusCode).To(Equal(int32(200)))
+			Expect(output.Get.Body).To(BeEmpty())
+			Expect(output.Process.EdgesBySubject["https://id.parliament.uk/43RHonMf"].Edges).To(HaveKey("https://id.parliament.uk/schema/memberHasParliamentaryIncumbency"))
+		})
+
+		It("reports failures getting in the output", func() {
+			httpmock.RegisterResponder("GET", uri, httpmock.NewStringResponder(500, "Error"))
+
+			output, err := client.GetAndProcess(context.Background(), &GetAndProcessInput{Get: &GetInput{Uri: uri}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output.Get.Error).To(Equal("Received 500 status code from " + uri + ": Error"))
+			Expect(output.Process).To(BeNil())
+		})
+
+		It("requires a request", func() {
+			_, err := client.GetAndProcess(context.Background(), &GetAndProcessInput{})
+
+			Expect(status.Code(err)).To(Equal(codes.InvalidArgument))
+		})
+	})
+})
@@ -0,0 +1,765 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: ext/types/graph.proto
+
+package graph
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type Term_Kind int32
+
+const (
+	Term_IRI        Term_Kind = 0
+	Term_LITERAL    Term_Kind = 1
+	Term_BLANK_NODE Term_Kind = 2
+)
+
+var Term_Kind_name = map[int32]string{
+	0: "IRI",
+	1: "LITERAL",
+	2: "BLANK_NODE",
+}
+
+var Term_Kind_value = map[string]int32{
+	"IRI":        0,
+	"LITERAL":    1,
+	"BLANK_NODE": 2,
+}
+
+func (x Term_Kind) String() string {
+	return proto.EnumName(Term_Kind_name, int32(x))
+}
+
+func (Term_Kind) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{0, 0}
+}
+
+// Term is an IRI, literal or blank node
+type Term struct {
+	Kind                 Term_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=graph.Term_Kind" json:"kind,omitempty"`
+	Value                string    `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Datatype             string    `protobuf:"bytes,3,opt,name=datatype,proto3" json:"datatype,omitempty"`
+	Language             string    `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Term) Reset()         { *m = Term{} }
+func (m *Term) String() string { return proto.CompactTextString(m) }
+func (*Term) ProtoMessage()    {}
+func (*Term) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{0}
+}
+
+func (m *Term) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Term.Unmarshal(m, b)
+}
+func (m *Term) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Term.Marshal(b, m, deterministic)
+}
+func (m *Term) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Term.Merge(m, src)
+}
+func (m *Term) XXX_Size() int {
+	return xxx_messageInfo_Term.Size(m)
+}
+func (m *Term) XXX_DiscardUnknown() {
+	xxx_messageInfo_Term.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Term proto.InternalMessageInfo
+
+func (m *Term) GetKind() Term_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Term_IRI
+}
+
+func (m *Term) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *Term) GetDatatype() string {
+	if m != nil {
+		return m.Datatype
+	}
+	return ""
+}
+
+func (m *Term) GetLanguage() string {
+	if m != nil {
+		return m.Language
+	}
+	return ""
+}
+
+// Triple is a statement, its subject keyed as in statementsBySubject with blank nodes prefixed by _:
+type Triple struct {
+	Subject              string    `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Predicate            string    `protobuf:"bytes,2,opt,name=predicate,proto3" json:"predicate,omitempty"`
+	Object               *Term     `protobuf:"bytes,4,opt,name=object,proto3" json:"object,omitempty"`
+	SubjectKind          Term_Kind `protobuf:"varint,5,opt,name=subjectKind,proto3,enum=graph.Term_Kind" json:"subjectKind,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Triple) Reset()         { *m = Triple{} }
+func (m *Triple) String() string { return proto.CompactTextString(m) }
+func (*Triple) ProtoMessage()    {}
+func (*Triple) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{1}
+}
+
+func (m *Triple) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Triple.Unmarshal(m, b)
+}
+func (m *Triple) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Triple.Marshal(b, m, deterministic)
+}
+func (m *Triple) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Triple.Merge(m, src)
+}
+func (m *Triple) XXX_Size() int {
+	return xxx_messageInfo_Triple.Size(m)
+}
+func (m *Triple) XXX_DiscardUnknown() {
+	xxx_messageInfo_Triple.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Triple proto.InternalMessageInfo
+
+func (m *Triple) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *Triple) GetPredicate() string {
+	if m != nil {
+		return m.Predicate
+	}
+	return ""
+}
+
+func (m *Triple) GetObject() *Term {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+func (m *Triple) GetSubjectKind() Term_Kind {
+	if m != nil {
+		return m.SubjectKind
+	}
+	return Term_IRI
+}
+
+// Statements are the triples sharing a subject
+type Statements struct {
+	Triples              []*Triple `protobuf:"bytes,1,rep,name=triples,proto3" json:"triples,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *Statements) Reset()         { *m = Statements{} }
+func (m *Statements) String() string { return proto.CompactTextString(m) }
+func (*Statements) ProtoMessage()    {}
+func (*Statements) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{2}
+}
+
+func (m *Statements) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Statements.Unmarshal(m, b)
+}
+func (m *Statements) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Statements.Marshal(b, m, deterministic)
+}
+func (m *Statements) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Statements.Merge(m, src)
+}
+func (m *Statements) XXX_Size() int {
+	return xxx_messageInfo_Statements.Size(m)
+}
+func (m *Statements) XXX_DiscardUnknown() {
+	xxx_messageInfo_Statements.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Statements proto.InternalMessageInfo
+
+func (m *Statements) GetTriples() []*Triple {
+	if m != nil {
+		return m.Triples
+	}
+	return nil
+}
+
+// Objects are the IRIs a subject links to through one edge
+type Objects struct {
+	Uris                 []string `protobuf:"bytes,1,rep,name=uris,proto3" json:"uris,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Objects) Reset()         { *m = Objects{} }
+func (m *Objects) String() string { return proto.CompactTextString(m) }
+func (*Objects) ProtoMessage()    {}
+func (*Objects) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{3}
+}
+
+func (m *Objects) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Objects.Unmarshal(m, b)
+}
+func (m *Objects) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Objects.Marshal(b, m, deterministic)
+}
+func (m *Objects) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Objects.Merge(m, src)
+}
+func (m *Objects) XXX_Size() int {
+	return xxx_messageInfo_Objects.Size(m)
+}
+func (m *Objects) XXX_DiscardUnknown() {
+	xxx_messageInfo_Objects.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Objects proto.InternalMessageInfo
+
+func (m *Objects) GetUris() []string {
+	if m != nil {
+		return m.Uris
+	}
+	return nil
+}
+
+// Edges are a subject's links to other IRIs, keyed by edge name
+type Edges struct {
+	Edges                map[string]*Objects `protobuf:"bytes,1,rep,name=edges,proto3" json:"edges,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *Edges) Reset()         { *m = Edges{} }
+func (m *Edges) String() string { return proto.CompactTextString(m) }
+func (*Edges) ProtoMessage()    {}
+func (*Edges) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{4}
+}
+
+func (m *Edges) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Edges.Unmarshal(m, b)
+}
+func (m *Edges) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Edges.Marshal(b, m, deterministic)
+}
+func (m *Edges) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Edges.Merge(m, src)
+}
+func (m *Edges) XXX_Size() int {
+	return xxx_messageInfo_Edges.Size(m)
+}
+func (m *Edges) XXX_DiscardUnknown() {
+	xxx_messageInfo_Edges.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Edges proto.InternalMessageInfo
+
+func (m *Edges) GetEdges() map[string]*Objects {
+	if m != nil {
+		return m.Edges
+	}
+	return nil
+}
+
+// Subjects are the subjects of one requested type
+type Subjects struct {
+	Subjects             []string `protobuf:"bytes,1,rep,name=subjects,proto3" json:"subjects,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Subjects) Reset()         { *m = Subjects{} }
+func (m *Subjects) String() string { return proto.CompactTextString(m) }
+func (*Subjects) ProtoMessage()    {}
+func (*Subjects) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{5}
+}
+
+func (m *Subjects) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Subjects.Unmarshal(m, b)
+}
+func (m *Subjects) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Subjects.Marshal(b, m, deterministic)
+}
+func (m *Subjects) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Subjects.Merge(m, src)
+}
+func (m *Subjects) XXX_Size() int {
+	return xxx_messageInfo_Subjects.Size(m)
+}
+func (m *Subjects) XXX_DiscardUnknown() {
+	xxx_messageInfo_Subjects.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Subjects proto.InternalMessageInfo
+
+func (m *Subjects) GetSubjects() []string {
+	if m != nil {
+		return m.Subjects
+	}
+	return nil
+}
+
+// Graph is a processed response body
+type Graph struct {
+	StatementsBySubject  map[string]*Statements `protobuf:"bytes,1,rep,name=statementsBySubject,proto3" json:"statementsBySubject,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	EdgesBySubject       map[string]*Edges      `protobuf:"bytes,2,rep,name=edgesBySubject,proto3" json:"edgesBySubject,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SubjectsByType       []*Subjects            `protobuf:"bytes,3,rep,name=subjectsByType,proto3" json:"subjectsByType,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *Graph) Reset()         { *m = Graph{} }
+func (m *Graph) String() string { return proto.CompactTextString(m) }
+func (*Graph) ProtoMessage()    {}
+func (*Graph) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{6}
+}
+
+func (m *Graph) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Graph.Unmarshal(m, b)
+}
+func (m *Graph) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Graph.Marshal(b, m, deterministic)
+}
+func (m *Graph) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Graph.Merge(m, src)
+}
+func (m *Graph) XXX_Size() int {
+	return xxx_messageInfo_Graph.Size(m)
+}
+func (m *Graph) XXX_DiscardUnknown() {
+	xxx_messageInfo_Graph.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Graph proto.InternalMessageInfo
+
+func (m *Graph) GetStatementsBySubject() map[string]*Statements {
+	if m != nil {
+		return m.StatementsBySubject
+	}
+	return nil
+}
+
+func (m *Graph) GetEdgesBySubject() map[string]*Edges {
+	if m != nil {
+		return m.EdgesBySubject
+	}
+	return nil
+}
+
+func (m *Graph) GetSubjectsByType() []*Subjects {
+	if m != nil {
+		return m.SubjectsByType
+	}
+	return nil
+}
+
+// Results is a SPARQL SELECT result set, or the answer to an ASK query
+type Results struct {
+	Variables            []string       `protobuf:"bytes,1,rep,name=variables,proto3" json:"variables,omitempty"`
+	Rows                 []*Results_Row `protobuf:"bytes,2,rep,name=rows,proto3" json:"rows,omitempty"`
+	Ask                  bool           `protobuf:"varint,3,opt,name=ask,proto3" json:"ask,omitempty"`
+	Boolean              bool           `protobuf:"varint,4,opt,name=boolean,proto3" json:"boolean,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *Results) Reset()         { *m = Results{} }
+func (m *Results) String() string { return proto.CompactTextString(m) }
+func (*Results) ProtoMessage()    {}
+func (*Results) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{7}
+}
+
+func (m *Results) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Results.Unmarshal(m, b)
+}
+func (m *Results) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Results.Marshal(b, m, deterministic)
+}
+func (m *Results) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Results.Merge(m, src)
+}
+func (m *Results) XXX_Size() int {
+	return xxx_messageInfo_Results.Size(m)
+}
+func (m *Results) XXX_DiscardUnknown() {
+	xxx_messageInfo_Results.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Results proto.InternalMessageInfo
+
+func (m *Results) GetVariables() []string {
+	if m != nil {
+		return m.Variables
+	}
+	return nil
+}
+
+func (m *Results) GetRows() []*Results_Row {
+	if m != nil {
+		return m.Rows
+	}
+	return nil
+}
+
+func (m *Results) GetAsk() bool {
+	if m != nil {
+		return m.Ask
+	}
+	return false
+}
+
+func (m *Results) GetBoolean() bool {
+	if m != nil {
+		return m.Boolean
+	}
+	return false
+}
+
+type Results_Row struct {
+	Bindings             map[string]*Term `protobuf:"bytes,1,rep,name=bindings,proto3" json:"bindings,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *Results_Row) Reset()         { *m = Results_Row{} }
+func (m *Results_Row) String() string { return proto.CompactTextString(m) }
+func (*Results_Row) ProtoMessage()    {}
+func (*Results_Row) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{7, 0}
+}
+
+func (m *Results_Row) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Results_Row.Unmarshal(m, b)
+}
+func (m *Results_Row) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Results_Row.Marshal(b, m, deterministic)
+}
+func (m *Results_Row) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Results_Row.Merge(m, src)
+}
+func (m *Results_Row) XXX_Size() int {
+	return xxx_messageInfo_Results_Row.Size(m)
+}
+func (m *Results_Row) XXX_DiscardUnknown() {
+	xxx_messageInfo_Results_Row.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Results_Row proto.InternalMessageInfo
+
+func (m *Results_Row) GetBindings() map[string]*Term {
+	if m != nil {
+		return m.Bindings
+	}
+	return nil
+}
+
+// Error describes a failure, as errorDetails does in JSON responses
+type Error struct {
+	Kind                 string   `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Message              string   `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	StatusCode           int32    `protobuf:"varint,3,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
+	Uri                  string   `protobuf:"bytes,4,opt,name=uri,proto3" json:"uri,omitempty"`
+	Retryable            bool     `protobuf:"varint,5,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	AttemptErrors        []string `protobuf:"bytes,6,rep,name=attemptErrors,proto3" json:"attemptErrors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Error) Reset()         { *m = Error{} }
+func (m *Error) String() string { return proto.CompactTextString(m) }
+func (*Error) ProtoMessage()    {}
+func (*Error) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{8}
+}
+
+func (m *Error) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Error.Unmarshal(m, b)
+}
+func (m *Error) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Error.Marshal(b, m, deterministic)
+}
+func (m *Error) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Error.Merge(m, src)
+}
+func (m *Error) XXX_Size() int {
+	return xxx_messageInfo_Error.Size(m)
+}
+func (m *Error) XXX_DiscardUnknown() {
+	xxx_messageInfo_Error.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Error proto.InternalMessageInfo
+
+func (m *Error) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Error) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Error) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *Error) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+func (m *Error) GetRetryable() bool {
+	if m != nil {
+		return m.Retryable
+	}
+	return false
+}
+
+func (m *Error) GetAttemptErrors() []string {
+	if m != nil {
+		return m.AttemptErrors
+	}
+	return nil
+}
+
+// Response is the binary form of the JSON response returned across the FFI boundary
+type Response struct {
+	Graph                *Graph   `protobuf:"bytes,1,opt,name=graph,proto3" json:"graph,omitempty"`
+	StatusCode           int32    `protobuf:"varint,2,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
+	Attempts             int32    `protobuf:"varint,3,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	Cache                string   `protobuf:"bytes,4,opt,name=cache,proto3" json:"cache,omitempty"`
+	Uri                  string   `protobuf:"bytes,5,opt,name=uri,proto3" json:"uri,omitempty"`
+	Error                string   `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode            string   `protobuf:"bytes,7,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	ErrorDetails         *Error   `protobuf:"bytes,8,opt,name=errorDetails,proto3" json:"errorDetails,omitempty"`
+	Pages                int32    `protobuf:"varint,9,opt,name=pages,proto3" json:"pages,omitempty"`
+	Results              *Results `protobuf:"bytes,10,opt,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Response) Reset()         { *m = Response{} }
+func (m *Response) String() string { return proto.CompactTextString(m) }
+func (*Response) ProtoMessage()    {}
+func (*Response) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd6d498b01016676, []int{9}
+}
+
+func (m *Response) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Response.Unmarshal(m, b)
+}
+func (m *Response) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Response.Marshal(b, m, deterministic)
+}
+func (m *Response) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Response.Merge(m, src)
+}
+func (m *Response) XXX_Size() int {
+	return xxx_messageInfo_Response.Size(m)
+}
+func (m *Response) XXX_DiscardUnknown() {
+	xxx_messageInfo_Response.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Response proto.InternalMessageInfo
+
+func (m *Response) GetGraph() *Graph {
+	if m != nil {
+		return m.Graph
+	}
+	return nil
+}
+
+func (m *Response) GetStatusCode() int32 {
+	if m != nil {
+		return m.StatusCode
+	}
+	return 0
+}
+
+func (m *Response) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *Response) GetCache() string {
+	if m != nil {
+		return m.Cache
+	}
+	return ""
+}
+
+func (m *Response) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+func (m *Response) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *Response) GetErrorCode() string {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return ""
+}
+
+func (m *Response) GetErrorDetails() *Error {
+	if m != nil {
+		return m.ErrorDetails
+	}
+	return nil
+}
+
+func (m *Response) GetPages() int32 {
+	if m != nil {
+		return m.Pages
+	}
+	return 0
+}
+
+func (m *Response) GetResults() *Results {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("graph.Term_Kind", Term_Kind_name, Term_Kind_value)
+	proto.RegisterType((*Term)(nil), "graph.Term")
+	proto.RegisterType((*Triple)(nil), "graph.Triple")
+	proto.RegisterType((*Statements)(nil), "graph.Statements")
+	proto.RegisterType((*Objects)(nil), "graph.Objects")
+	proto.RegisterType((*Edges)(nil), "graph.Edges")
+	proto.RegisterMapType((map[string]*Objects)(nil), "graph.Edges.EdgesEntry")
+	proto.RegisterType((*Subjects)(nil), "graph.Subjects")
+	proto.RegisterType((*Graph)(nil), "graph.Graph")
+	proto.RegisterMapType((map[string]*Edges)(nil), "graph.Graph.EdgesBySubjectEntry")
+	proto.RegisterMapType((map[string]*Statements)(nil), "graph.Graph.StatementsBySubjectEntry")
+	proto.RegisterType((*Results)(nil), "graph.Results")
+	proto.RegisterType((*Results_Row)(nil), "graph.Results.Row")
+	proto.RegisterMapType((map[string]*Term)(nil), "graph.Results.Row.BindingsEntry")
+	proto.RegisterType((*Error)(nil), "graph.Error")
+	proto.RegisterType((*Response)(nil), "graph.Response")
+}
+
+func init() { proto.RegisterFile("ext/types/graph.proto", fileDescriptor_fd6d498b01016676) }
+
+var fileDescriptor_fd6d498b01016676 = []byte{
+	// 830 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x55, 0x4d, 0x6f, 0xe3, 0x44,
+	0x18, 0xc6, 0x89, 0x1d, 0xdb, 0x6f, 0xb6, 0x21, 0xcc, 0x2e, 0xc2, 0xb2, 0x00, 0x05, 0x53, 0x76,
+	0x7b, 0x80, 0x04, 0x05, 0x10, 0x08, 0x71, 0xd9, 0xb0, 0x11, 0x2d, 0x5b, 0x6d, 0xa5, 0x69, 0x25,
+	0x04, 0x17, 0x34, 0x49, 0x46, 0xae, 0x49, 0x62, 0x5b, 0x33, 0xe3, 0x96, 0xdc, 0xb8, 0x23, 0xc4,
+	0x4f, 0xe0, 0x8a, 0xc4, 0x1f, 0xe0, 0xe7, 0xad, 0xe6, 0xcb, 0xb1, 0xd3, 0xec, 0xc5, 0x9a, 0xf7,
+	0x63, 0x9e, 0x79, 0xde, 0x4f, 0xc3, 0xbb, 0xf4, 0x77, 0x31, 0x11, 0xbb, 0x92, 0xf2, 0x49, 0xca,
+	0x48, 0x79, 0x3b, 0x2e, 0x59, 0x21, 0x0a, 0xe4, 0x29, 0x21, 0xf9, 0xd7, 0x01, 0xf7, 0x86, 0xb2,
+	0x2d, 0x3a, 0x05, 0x77, 0x9d, 0xe5, 0xab, 0xc8, 0x19, 0x39, 0x67, 0x83, 0xe9, 0x70, 0xac, 0x7d,
+	0xa5, 0x69, 0xfc, 0x32, 0xcb, 0x57, 0x58, 0x59, 0xd1, 0x13, 0xf0, 0xee, 0xc8, 0xa6, 0xa2, 0x51,
+	0x67, 0xe4, 0x9c, 0x85, 0x58, 0x0b, 0x28, 0x86, 0x60, 0x45, 0x04, 0x91, 0x8f, 0x44, 0x5d, 0x65,
+	0xa8, 0x65, 0x69, 0xdb, 0x90, 0x3c, 0xad, 0x48, 0x4a, 0x23, 0x57, 0xdb, 0xac, 0x9c, 0x7c, 0x0a,
+	0xae, 0xc4, 0x46, 0x3e, 0x74, 0x2f, 0xf0, 0xc5, 0xf0, 0x2d, 0xd4, 0x07, 0xff, 0xf2, 0xe2, 0x66,
+	0x8e, 0x9f, 0x5f, 0x0e, 0x1d, 0x34, 0x00, 0x98, 0x5d, 0x3e, 0x7f, 0xf5, 0xf2, 0xd7, 0x57, 0x57,
+	0x2f, 0xe6, 0xc3, 0x4e, 0xf2, 0x8f, 0x03, 0xbd, 0x1b, 0x96, 0x95, 0x1b, 0x8a, 0x22, 0xf0, 0x79,
+	0xb5, 0xf8, 0x8d, 0x2e, 0x85, 0xe2, 0x1b, 0x62, 0x2b, 0xa2, 0xf7, 0x21, 0x2c, 0x19, 0x5d, 0x65,
+	0x4b, 0x22, 0x2c, 0xc9, 0xbd, 0x02, 0x7d, 0x0c, 0xbd, 0x42, 0x5f, 0x93, 0x54, 0xfa, 0xd3, 0x7e,
+	0x23, 0x4c, 0x6c, 0x4c, 0x68, 0x0a, 0x7d, 0x83, 0x26, 0xc9, 0x45, 0xde, 0x1b, 0x12, 0xd2, 0x74,
+	0xfa, 0xd1, 0x0d, 0xba, 0x43, 0x37, 0xf9, 0x0a, 0xe0, 0x5a, 0x10, 0x41, 0xb7, 0x34, 0x17, 0x1c,
+	0x3d, 0x03, 0x5f, 0x28, 0xba, 0x3c, 0x72, 0x46, 0xdd, 0xb3, 0xfe, 0xf4, 0xc4, 0x62, 0x28, 0x2d,
+	0xb6, 0xd6, 0xe4, 0x03, 0xf0, 0xaf, 0x14, 0x14, 0x47, 0x08, 0xdc, 0x8a, 0x65, 0xfa, 0x42, 0x88,
+	0xd5, 0x39, 0xf9, 0xc3, 0x01, 0x6f, 0xbe, 0x4a, 0x29, 0x47, 0x9f, 0x81, 0x47, 0xe5, 0xc1, 0xe0,
+	0xbd, 0x67, 0xf0, 0x94, 0x51, 0x7f, 0xe7, 0xb9, 0x60, 0x3b, 0xac, 0xbd, 0xe2, 0x73, 0x80, 0xbd,
+	0x12, 0x0d, 0xa1, 0xbb, 0xa6, 0x3b, 0x93, 0x2f, 0x79, 0x44, 0xa7, 0xcd, 0x62, 0xf6, 0xa7, 0x03,
+	0x03, 0x67, 0xb8, 0x98, 0xe2, 0x7e, 0xdb, 0xf9, 0xc6, 0x49, 0x9e, 0x42, 0x70, 0x5d, 0x19, 0x8a,
+	0x31, 0x04, 0x26, 0x72, 0x4b, 0xb3, 0x96, 0x93, 0xbf, 0xbb, 0xe0, 0xfd, 0x20, 0x41, 0xd0, 0x4f,
+	0xf0, 0x98, 0xd7, 0xa9, 0x98, 0xed, 0xae, 0xeb, 0x6a, 0x49, 0xe2, 0x9f, 0x98, 0x97, 0x94, 0xeb,
+	0xf8, 0xfa, 0xa1, 0x9f, 0x0e, 0xe3, 0x18, 0x02, 0x3a, 0x87, 0x81, 0x8a, 0x6e, 0x8f, 0xd9, 0x51,
+	0x98, 0xa3, 0x16, 0xe6, 0xbc, 0xe5, 0xa2, 0xe1, 0x0e, 0xee, 0xa1, 0xaf, 0x61, 0x60, 0x89, 0xcf,
+	0x76, 0x37, 0xba, 0x77, 0x25, 0xd2, 0xdb, 0x06, 0xc9, 0x46, 0x8c, 0x0f, 0xdc, 0xe2, 0x9f, 0x21,
+	0x7a, 0x13, 0xe7, 0x23, 0x59, 0x7e, 0xd6, 0xce, 0xf2, 0x3b, 0x16, 0xbd, 0x46, 0x68, 0x24, 0x3a,
+	0xbe, 0x82, 0xc7, 0x47, 0xa8, 0x1f, 0x41, 0x4d, 0xda, 0xa8, 0x8f, 0x9a, 0xad, 0xd0, 0xac, 0xdc,
+	0x9f, 0x1d, 0xf0, 0x31, 0xe5, 0xd5, 0x46, 0x70, 0x39, 0x1b, 0x77, 0x84, 0x65, 0x64, 0x61, 0x5b,
+	0x32, 0xc4, 0x7b, 0x05, 0x7a, 0x0a, 0x2e, 0x2b, 0xee, 0xb9, 0x49, 0x27, 0x32, 0x80, 0xe6, 0xee,
+	0x18, 0x17, 0xf7, 0x58, 0xd9, 0x25, 0x17, 0xc2, 0xd7, 0x6a, 0xce, 0x03, 0x2c, 0x8f, 0x72, 0x1a,
+	0x17, 0x45, 0xb1, 0xa1, 0x24, 0x57, 0x63, 0x15, 0x60, 0x2b, 0xc6, 0x7f, 0x39, 0xd0, 0xc5, 0xc5,
+	0x3d, 0xfa, 0x0e, 0x82, 0x45, 0x96, 0xaf, 0xb2, 0x3c, 0xb5, 0xbd, 0x3b, 0x7a, 0x88, 0x3f, 0x9e,
+	0x19, 0x17, 0x5d, 0xae, 0xfa, 0x46, 0x7c, 0x0e, 0x27, 0x2d, 0xd3, 0x91, 0x74, 0x7c, 0xd4, 0x4e,
+	0x47, 0x6b, 0xae, 0x1b, 0xd9, 0xf8, 0x4f, 0x8e, 0x12, 0x63, 0x05, 0x93, 0x83, 0x56, 0xaf, 0xbb,
+	0xd0, 0x2c, 0xb7, 0x08, 0xfc, 0x2d, 0xe5, 0x9c, 0xa4, 0x1a, 0x26, 0xc4, 0x56, 0x44, 0x1f, 0x02,
+	0xc8, 0x5e, 0xac, 0xf8, 0xf7, 0xc5, 0x4a, 0xaf, 0x38, 0x0f, 0x37, 0x34, 0x92, 0x50, 0xc5, 0x32,
+	0xb3, 0xdf, 0xe4, 0x51, 0xe6, 0x9a, 0x51, 0xc1, 0x76, 0x32, 0xb7, 0x6a, 0x85, 0x04, 0x78, 0xaf,
+	0x40, 0xa7, 0x70, 0x42, 0x84, 0xa0, 0xdb, 0x52, 0x28, 0x36, 0x3c, 0xea, 0xa9, 0x6a, 0xb4, 0x95,
+	0xc9, 0xff, 0x1d, 0x08, 0x30, 0xe5, 0x65, 0x91, 0x73, 0x2a, 0x0b, 0xae, 0x62, 0x8a, 0x9c, 0x56,
+	0xc1, 0x55, 0xbb, 0x63, 0x6d, 0x3a, 0xa0, 0xd9, 0x79, 0x40, 0x33, 0x86, 0xc0, 0xbc, 0xc0, 0x4d,
+	0x10, 0xb5, 0x2c, 0x37, 0xfb, 0x92, 0x2c, 0x6f, 0xed, 0x92, 0xd6, 0x82, 0x0d, 0xcc, 0xdb, 0x07,
+	0xf6, 0x04, 0x3c, 0x2a, 0xe9, 0x45, 0x3d, 0xed, 0xa7, 0x04, 0x19, 0xae, 0x3a, 0xa8, 0x87, 0x7d,
+	0x65, 0xd9, 0x2b, 0xd0, 0xe7, 0xf0, 0x48, 0x09, 0x2f, 0xa8, 0x20, 0xd9, 0x86, 0x47, 0x41, 0xbb,
+	0x67, 0xa5, 0x09, 0xb7, 0x3c, 0xe4, 0x2b, 0x25, 0x91, 0x9b, 0x2e, 0x54, 0x34, 0xb5, 0x80, 0xce,
+	0xc0, 0x67, 0xba, 0x5f, 0x22, 0x68, 0xad, 0x2c, 0xd3, 0x45, 0xd8, 0x9a, 0x67, 0x5f, 0xfe, 0x32,
+	0x4d, 0x33, 0x71, 0x5b, 0x2d, 0xc6, 0xcb, 0x62, 0x3b, 0xa9, 0xd6, 0x25, 0x61, 0x9b, 0x8c, 0xc8,
+	0x71, 0x9b, 0xa4, 0xac, 0xd8, 0xe6, 0x44, 0x64, 0x77, 0x74, 0x72, 0xf0, 0x67, 0x5c, 0xf4, 0xd4,
+	0xaf, 0xf1, 0x8b, 0xd7, 0x03, 0x00, 0x2c, 0x65, 0x0c, 0xa4, 0x33, 0x07, 0x00, 0x00,
+}
@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// MarshalJSON writes kinds by name, such as "blank_node", so JSON readers need not know the enum's numbering
+func (k Term_Kind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToLower(k.String()))
+}
+
+func (k *Term_Kind) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+
+	value, ok := Term_Kind_value[strings.ToUpper(name)]
+	if !ok {
+		return fmt.Errorf("Unknown term kind %v", name)
+	}
+	*k = Term_Kind(value)
+
+	return nil
+}
@@ -4,8 +4,13 @@
 package net
 
 import (
+	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	graph "github.com/ukparliament/gromnative/ext/types/graph"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
 	math "math"
 )
 
@@ -21,11 +26,18 @@ var _ = math.Inf
 const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 
 type GetInput struct {
-	Uri                  string             `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
-	Headers              []*GetInput_Header `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+	Uri     string                `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Headers []*GetInput_Header    `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
+	Retry   *GetInput_RetryPolicy `protobuf:"bytes,3,opt,name=retry,proto3" json:"retry,omitempty"`
+	// Zero means no limit
+	MaxBodyBytes int64 `protobuf:"varint,4,opt,name=maxBodyBytes,proto3" json:"maxBodyBytes,omitempty"`
+	MaxTriples   int64 `protobuf:"varint,5,opt,name=maxTriples,proto3" json:"maxTriples,omitempty"`
+	// Status codes treated as success, empty means the defaults in the net package
+	AcceptedStatusCodes  []int32               `protobuf:"varint,6,rep,packed,name=acceptedStatusCodes,proto3" json:"acceptedStatusCodes,omitempty"`
+	Sparql               *GetInput_SparqlQuery `protobuf:"bytes,7,opt,name=sparql,proto3" json:"sparql,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
 func (m *GetInput) Reset()         { *m = GetInput{} }
@@ -67,6 +79,41 @@ func (m *GetInput) GetHeaders() []*GetInput_Header {
 	return nil
 }
 
+func (m *GetInput) GetRetry() *GetInput_RetryPolicy {
+	if m != nil {
+		return m.Retry
+	}
+	return nil
+}
+
+func (m *GetInput) GetMaxBodyBytes() int64 {
+	if m != nil {
+		return m.MaxBodyBytes
+	}
+	return 0
+}
+
+func (m *GetInput) GetMaxTriples() int64 {
+	if m != nil {
+		return m.MaxTriples
+	}
+	return 0
+}
+
+func (m *GetInput) GetAcceptedStatusCodes() []int32 {
+	if m != nil {
+		return m.AcceptedStatusCodes
+	}
+	return nil
+}
+
+func (m *GetInput) GetSparql() *GetInput_SparqlQuery {
+	if m != nil {
+		return m.Sparql
+	}
+	return nil
+}
+
 type GetInput_Header struct {
 	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
@@ -114,11 +161,247 @@ func (m *GetInput_Header) GetValue() string {
 	return ""
 }
 
+// Zero values fall back to the defaults in the net package
+type GetInput_RetryPolicy struct {
+	MaxAttempts          int32    `protobuf:"varint,1,opt,name=maxAttempts,proto3" json:"maxAttempts,omitempty"`
+	BackoffBase          int64    `protobuf:"varint,2,opt,name=backoffBase,proto3" json:"backoffBase,omitempty"`
+	BackoffCap           int64    `protobuf:"varint,3,opt,name=backoffCap,proto3" json:"backoffCap,omitempty"`
+	Jitter               float64  `protobuf:"fixed64,4,opt,name=jitter,proto3" json:"jitter,omitempty"`
+	RetryableStatusCodes []int32  `protobuf:"varint,5,rep,packed,name=retryableStatusCodes,proto3" json:"retryableStatusCodes,omitempty"`
+	RespectRetryAfter    bool     `protobuf:"varint,6,opt,name=respectRetryAfter,proto3" json:"respectRetryAfter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetInput_RetryPolicy) Reset()         { *m = GetInput_RetryPolicy{} }
+func (m *GetInput_RetryPolicy) String() string { return proto.CompactTextString(m) }
+func (*GetInput_RetryPolicy) ProtoMessage()    {}
+func (*GetInput_RetryPolicy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{0, 1}
+}
+
+func (m *GetInput_RetryPolicy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetInput_RetryPolicy.Unmarshal(m, b)
+}
+func (m *GetInput_RetryPolicy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetInput_RetryPolicy.Marshal(b, m, deterministic)
+}
+func (m *GetInput_RetryPolicy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetInput_RetryPolicy.Merge(m, src)
+}
+func (m *GetInput_RetryPolicy) XXX_Size() int {
+	return xxx_messageInfo_GetInput_RetryPolicy.Size(m)
+}
+func (m *GetInput_RetryPolicy) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetInput_RetryPolicy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetInput_RetryPolicy proto.InternalMessageInfo
+
+func (m *GetInput_RetryPolicy) GetMaxAttempts() int32 {
+	if m != nil {
+		return m.MaxAttempts
+	}
+	return 0
+}
+
+func (m *GetInput_RetryPolicy) GetBackoffBase() int64 {
+	if m != nil {
+		return m.BackoffBase
+	}
+	return 0
+}
+
+func (m *GetInput_RetryPolicy) GetBackoffCap() int64 {
+	if m != nil {
+		return m.BackoffCap
+	}
+	return 0
+}
+
+func (m *GetInput_RetryPolicy) GetJitter() float64 {
+	if m != nil {
+		return m.Jitter
+	}
+	return 0
+}
+
+func (m *GetInput_RetryPolicy) GetRetryableStatusCodes() []int32 {
+	if m != nil {
+		return m.RetryableStatusCodes
+	}
+	return nil
+}
+
+func (m *GetInput_RetryPolicy) GetRespectRetryAfter() bool {
+	if m != nil {
+		return m.RespectRetryAfter
+	}
+	return false
+}
+
+// A query POSTed to the SPARQL endpoint at uri in place of a GET
+type GetInput_SparqlQuery struct {
+	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Encoding             string   `protobuf:"bytes,2,opt,name=encoding,proto3" json:"encoding,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetInput_SparqlQuery) Reset()         { *m = GetInput_SparqlQuery{} }
+func (m *GetInput_SparqlQuery) String() string { return proto.CompactTextString(m) }
+func (*GetInput_SparqlQuery) ProtoMessage()    {}
+func (*GetInput_SparqlQuery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{0, 2}
+}
+
+func (m *GetInput_SparqlQuery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetInput_SparqlQuery.Unmarshal(m, b)
+}
+func (m *GetInput_SparqlQuery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetInput_SparqlQuery.Marshal(b, m, deterministic)
+}
+func (m *GetInput_SparqlQuery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetInput_SparqlQuery.Merge(m, src)
+}
+func (m *GetInput_SparqlQuery) XXX_Size() int {
+	return xxx_messageInfo_GetInput_SparqlQuery.Size(m)
+}
+func (m *GetInput_SparqlQuery) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetInput_SparqlQuery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetInput_SparqlQuery proto.InternalMessageInfo
+
+func (m *GetInput_SparqlQuery) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *GetInput_SparqlQuery) GetEncoding() string {
+	if m != nil {
+		return m.Encoding
+	}
+	return ""
+}
+
+// Request is a request with any method, a GetInput being a GET or a SPARQL query
+type Request struct {
+	Method      string             `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	Uri         string             `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+	Headers     []*GetInput_Header `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty"`
+	Body        []byte             `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	ContentType string             `protobuf:"bytes,5,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	// Unsafe methods such as POST are retried like any other, so only set retry when repeating the request is harmless
+	Retry                *GetInput_RetryPolicy `protobuf:"bytes,6,opt,name=retry,proto3" json:"retry,omitempty"`
+	MaxBodyBytes         int64                 `protobuf:"varint,7,opt,name=maxBodyBytes,proto3" json:"maxBodyBytes,omitempty"`
+	AcceptedStatusCodes  []int32               `protobuf:"varint,8,rep,packed,name=acceptedStatusCodes,proto3" json:"acceptedStatusCodes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *Request) Reset()         { *m = Request{} }
+func (m *Request) String() string { return proto.CompactTextString(m) }
+func (*Request) ProtoMessage()    {}
+func (*Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{1}
+}
+
+func (m *Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Request.Unmarshal(m, b)
+}
+func (m *Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Request.Marshal(b, m, deterministic)
+}
+func (m *Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Request.Merge(m, src)
+}
+func (m *Request) XXX_Size() int {
+	return xxx_messageInfo_Request.Size(m)
+}
+func (m *Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_Request.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Request proto.InternalMessageInfo
+
+func (m *Request) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *Request) GetUri() string {
+	if m != nil {
+		return m.Uri
+	}
+	return ""
+}
+
+func (m *Request) GetHeaders() []*GetInput_Header {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *Request) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *Request) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *Request) GetRetry() *GetInput_RetryPolicy {
+	if m != nil {
+		return m.Retry
+	}
+	return nil
+}
+
+func (m *Request) GetMaxBodyBytes() int64 {
+	if m != nil {
+		return m.MaxBodyBytes
+	}
+	return 0
+}
+
+func (m *Request) GetAcceptedStatusCodes() []int32 {
+	if m != nil {
+		return m.AcceptedStatusCodes
+	}
+	return nil
+}
+
 type GetOutput struct {
-	Uri                  string   `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
-	Body                 []byte   `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
-	StatusCode           int32    `protobuf:"varint,3,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
-	Error                string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Uri           string   `protobuf:"bytes,1,opt,name=uri,proto3" json:"uri,omitempty"`
+	Body          []byte   `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	StatusCode    int32    `protobuf:"varint,3,opt,name=statusCode,proto3" json:"statusCode,omitempty"`
+	Error         string   `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	ContentType   string   `protobuf:"bytes,5,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Attempts      int32    `protobuf:"varint,6,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	AttemptErrors []string `protobuf:"bytes,7,rep,name=attemptErrors,proto3" json:"attemptErrors,omitempty"`
+	Cache         string   `protobuf:"bytes,8,opt,name=cache,proto3" json:"cache,omitempty"`
+	ErrorCode     string   `protobuf:"bytes,9,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	ErrorKind     string   `protobuf:"bytes,10,opt,name=errorKind,proto3" json:"errorKind,omitempty"`
+	Retryable     bool     `protobuf:"varint,11,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	// Body sizes as received and once Content-Encoding is decoded, zero for responses served from the cache
+	CompressedBytes      int64    `protobuf:"varint,12,opt,name=compressedBytes,proto3" json:"compressedBytes,omitempty"`
+	DecompressedBytes    int64    `protobuf:"varint,13,opt,name=decompressedBytes,proto3" json:"decompressedBytes,omitempty"`
+	Next                 string   `protobuf:"bytes,14,opt,name=next,proto3" json:"next,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -128,7 +411,7 @@ func (m *GetOutput) Reset()         { *m = GetOutput{} }
 func (m *GetOutput) String() string { return proto.CompactTextString(m) }
 func (*GetOutput) ProtoMessage()    {}
 func (*GetOutput) Descriptor() ([]byte, []int) {
-	return fileDescriptor_6a2e3e6b7e224274, []int{1}
+	return fileDescriptor_6a2e3e6b7e224274, []int{2}
 }
 
 func (m *GetOutput) XXX_Unmarshal(b []byte) error {
@@ -177,31 +460,599 @@ func (m *GetOutput) GetError() string {
 	return ""
 }
 
+func (m *GetOutput) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *GetOutput) GetAttempts() int32 {
+	if m != nil {
+		return m.Attempts
+	}
+	return 0
+}
+
+func (m *GetOutput) GetAttemptErrors() []string {
+	if m != nil {
+		return m.AttemptErrors
+	}
+	return nil
+}
+
+func (m *GetOutput) GetCache() string {
+	if m != nil {
+		return m.Cache
+	}
+	return ""
+}
+
+func (m *GetOutput) GetErrorCode() string {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return ""
+}
+
+func (m *GetOutput) GetErrorKind() string {
+	if m != nil {
+		return m.ErrorKind
+	}
+	return ""
+}
+
+func (m *GetOutput) GetRetryable() bool {
+	if m != nil {
+		return m.Retryable
+	}
+	return false
+}
+
+func (m *GetOutput) GetCompressedBytes() int64 {
+	if m != nil {
+		return m.CompressedBytes
+	}
+	return 0
+}
+
+func (m *GetOutput) GetDecompressedBytes() int64 {
+	if m != nil {
+		return m.DecompressedBytes
+	}
+	return 0
+}
+
+func (m *GetOutput) GetNext() string {
+	if m != nil {
+		return m.Next
+	}
+	return ""
+}
+
+type ProcessInput struct {
+	Body                 []byte            `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	ContentType          string            `protobuf:"bytes,2,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Types                []string          `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+	EdgeNaming           string            `protobuf:"bytes,4,opt,name=edgeNaming,proto3" json:"edgeNaming,omitempty"`
+	Prefixes             map[string]string `protobuf:"bytes,5,rep,name=prefixes,proto3" json:"prefixes,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	MaxTriples           int64             `protobuf:"varint,6,opt,name=maxTriples,proto3" json:"maxTriples,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *ProcessInput) Reset()         { *m = ProcessInput{} }
+func (m *ProcessInput) String() string { return proto.CompactTextString(m) }
+func (*ProcessInput) ProtoMessage()    {}
+func (*ProcessInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{3}
+}
+
+func (m *ProcessInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProcessInput.Unmarshal(m, b)
+}
+func (m *ProcessInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProcessInput.Marshal(b, m, deterministic)
+}
+func (m *ProcessInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProcessInput.Merge(m, src)
+}
+func (m *ProcessInput) XXX_Size() int {
+	return xxx_messageInfo_ProcessInput.Size(m)
+}
+func (m *ProcessInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProcessInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProcessInput proto.InternalMessageInfo
+
+func (m *ProcessInput) GetBody() []byte {
+	if m != nil {
+		return m.Body
+	}
+	return nil
+}
+
+func (m *ProcessInput) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *ProcessInput) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
+func (m *ProcessInput) GetEdgeNaming() string {
+	if m != nil {
+		return m.EdgeNaming
+	}
+	return ""
+}
+
+func (m *ProcessInput) GetPrefixes() map[string]string {
+	if m != nil {
+		return m.Prefixes
+	}
+	return nil
+}
+
+func (m *ProcessInput) GetMaxTriples() int64 {
+	if m != nil {
+		return m.MaxTriples
+	}
+	return 0
+}
+
+// ProcessOutput is a graph.Graph along with any error
+type ProcessOutput struct {
+	StatementsBySubject  map[string]*graph.Statements `protobuf:"bytes,1,rep,name=statementsBySubject,proto3" json:"statementsBySubject,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	EdgesBySubject       map[string]*graph.Edges      `protobuf:"bytes,2,rep,name=edgesBySubject,proto3" json:"edgesBySubject,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	SubjectsByType       []*graph.Subjects            `protobuf:"bytes,3,rep,name=subjectsByType,proto3" json:"subjectsByType,omitempty"`
+	Error                string                       `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	ErrorCode            string                       `protobuf:"bytes,5,opt,name=errorCode,proto3" json:"errorCode,omitempty"`
+	Results              *graph.Results               `protobuf:"bytes,6,opt,name=results,proto3" json:"results,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *ProcessOutput) Reset()         { *m = ProcessOutput{} }
+func (m *ProcessOutput) String() string { return proto.CompactTextString(m) }
+func (*ProcessOutput) ProtoMessage()    {}
+func (*ProcessOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{4}
+}
+
+func (m *ProcessOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ProcessOutput.Unmarshal(m, b)
+}
+func (m *ProcessOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ProcessOutput.Marshal(b, m, deterministic)
+}
+func (m *ProcessOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ProcessOutput.Merge(m, src)
+}
+func (m *ProcessOutput) XXX_Size() int {
+	return xxx_messageInfo_ProcessOutput.Size(m)
+}
+func (m *ProcessOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_ProcessOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ProcessOutput proto.InternalMessageInfo
+
+func (m *ProcessOutput) GetStatementsBySubject() map[string]*graph.Statements {
+	if m != nil {
+		return m.StatementsBySubject
+	}
+	return nil
+}
+
+func (m *ProcessOutput) GetEdgesBySubject() map[string]*graph.Edges {
+	if m != nil {
+		return m.EdgesBySubject
+	}
+	return nil
+}
+
+func (m *ProcessOutput) GetSubjectsByType() []*graph.Subjects {
+	if m != nil {
+		return m.SubjectsByType
+	}
+	return nil
+}
+
+func (m *ProcessOutput) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+func (m *ProcessOutput) GetErrorCode() string {
+	if m != nil {
+		return m.ErrorCode
+	}
+	return ""
+}
+
+func (m *ProcessOutput) GetResults() *graph.Results {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
+type GetAndProcessInput struct {
+	Get                  *GetInput     `protobuf:"bytes,1,opt,name=get,proto3" json:"get,omitempty"`
+	Process              *ProcessInput `protobuf:"bytes,2,opt,name=process,proto3" json:"process,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *GetAndProcessInput) Reset()         { *m = GetAndProcessInput{} }
+func (m *GetAndProcessInput) String() string { return proto.CompactTextString(m) }
+func (*GetAndProcessInput) ProtoMessage()    {}
+func (*GetAndProcessInput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{5}
+}
+
+func (m *GetAndProcessInput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAndProcessInput.Unmarshal(m, b)
+}
+func (m *GetAndProcessInput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAndProcessInput.Marshal(b, m, deterministic)
+}
+func (m *GetAndProcessInput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAndProcessInput.Merge(m, src)
+}
+func (m *GetAndProcessInput) XXX_Size() int {
+	return xxx_messageInfo_GetAndProcessInput.Size(m)
+}
+func (m *GetAndProcessInput) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAndProcessInput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAndProcessInput proto.InternalMessageInfo
+
+func (m *GetAndProcessInput) GetGet() *GetInput {
+	if m != nil {
+		return m.Get
+	}
+	return nil
+}
+
+func (m *GetAndProcessInput) GetProcess() *ProcessInput {
+	if m != nil {
+		return m.Process
+	}
+	return nil
+}
+
+type GetAndProcessOutput struct {
+	Get                  *GetOutput     `protobuf:"bytes,1,opt,name=get,proto3" json:"get,omitempty"`
+	Process              *ProcessOutput `protobuf:"bytes,2,opt,name=process,proto3" json:"process,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *GetAndProcessOutput) Reset()         { *m = GetAndProcessOutput{} }
+func (m *GetAndProcessOutput) String() string { return proto.CompactTextString(m) }
+func (*GetAndProcessOutput) ProtoMessage()    {}
+func (*GetAndProcessOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_6a2e3e6b7e224274, []int{6}
+}
+
+func (m *GetAndProcessOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAndProcessOutput.Unmarshal(m, b)
+}
+func (m *GetAndProcessOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAndProcessOutput.Marshal(b, m, deterministic)
+}
+func (m *GetAndProcessOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAndProcessOutput.Merge(m, src)
+}
+func (m *GetAndProcessOutput) XXX_Size() int {
+	return xxx_messageInfo_GetAndProcessOutput.Size(m)
+}
+func (m *GetAndProcessOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAndProcessOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAndProcessOutput proto.InternalMessageInfo
+
+func (m *GetAndProcessOutput) GetGet() *GetOutput {
+	if m != nil {
+		return m.Get
+	}
+	return nil
+}
+
+func (m *GetAndProcessOutput) GetProcess() *ProcessOutput {
+	if m != nil {
+		return m.Process
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*GetInput)(nil), "net.GetInput")
 	proto.RegisterType((*GetInput_Header)(nil), "net.GetInput.Header")
+	proto.RegisterType((*GetInput_RetryPolicy)(nil), "net.GetInput.RetryPolicy")
+	proto.RegisterType((*GetInput_SparqlQuery)(nil), "net.GetInput.SparqlQuery")
+	proto.RegisterType((*Request)(nil), "net.Request")
 	proto.RegisterType((*GetOutput)(nil), "net.GetOutput")
+	proto.RegisterType((*ProcessInput)(nil), "net.ProcessInput")
+	proto.RegisterMapType((map[string]string)(nil), "net.ProcessInput.PrefixesEntry")
+	proto.RegisterType((*ProcessOutput)(nil), "net.ProcessOutput")
+	proto.RegisterMapType((map[string]*graph.Edges)(nil), "net.ProcessOutput.EdgesBySubjectEntry")
+	proto.RegisterMapType((map[string]*graph.Statements)(nil), "net.ProcessOutput.StatementsBySubjectEntry")
+	proto.RegisterType((*GetAndProcessInput)(nil), "net.GetAndProcessInput")
+	proto.RegisterType((*GetAndProcessOutput)(nil), "net.GetAndProcessOutput")
 }
 
 func init() { proto.RegisterFile("ext/types/net.proto", fileDescriptor_6a2e3e6b7e224274) }
 
 var fileDescriptor_6a2e3e6b7e224274 = []byte{
-	// 260 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x50, 0x4d, 0x4b, 0xc3, 0x40,
-	0x10, 0x25, 0xdd, 0xb6, 0xda, 0xf1, 0x03, 0x59, 0x7b, 0x08, 0x3d, 0x48, 0x28, 0x1e, 0x02, 0xc2,
-	0x6e, 0x89, 0xff, 0x40, 0x0f, 0xd1, 0x8b, 0x42, 0x8e, 0xde, 0x36, 0x66, 0x48, 0x43, 0x9a, 0xdd,
-	0xb0, 0x99, 0x2d, 0xe6, 0xe2, 0x6f, 0x97, 0x6c, 0x08, 0x58, 0xe8, 0x6d, 0xde, 0xdb, 0x7d, 0xf3,
-	0xde, 0x3c, 0xb8, 0xc7, 0x1f, 0x92, 0xd4, 0xb7, 0xd8, 0x49, 0x8d, 0x24, 0x5a, 0x6b, 0xc8, 0x70,
-	0xa6, 0x91, 0xb6, 0xbf, 0x70, 0x99, 0x22, 0xbd, 0xeb, 0xd6, 0x11, 0xbf, 0x03, 0xe6, 0x6c, 0x15,
-	0x06, 0x51, 0x10, 0xaf, 0xb2, 0x61, 0xe4, 0x02, 0x2e, 0xf6, 0xa8, 0x0a, 0xb4, 0x5d, 0x38, 0x8b,
-	0x58, 0x7c, 0x95, 0xac, 0xc5, 0xa0, 0x9f, 0x14, 0xe2, 0xcd, 0x3f, 0x66, 0xd3, 0xa7, 0xcd, 0x0e,
-	0x96, 0x23, 0x35, 0xec, 0xaa, 0xb1, 0x9f, 0x76, 0xd5, 0xd8, 0xf3, 0x35, 0x2c, 0x8e, 0xea, 0xe0,
-	0x30, 0x9c, 0x79, 0x6e, 0x04, 0xdb, 0x12, 0x56, 0x29, 0xd2, 0xa7, 0xa3, 0xf3, 0x01, 0x38, 0xcc,
-	0x73, 0x53, 0xf4, 0x5e, 0x73, 0x9d, 0xf9, 0x99, 0x3f, 0x00, 0x74, 0xa4, 0xc8, 0x75, 0xaf, 0xa6,
-	0xc0, 0x90, 0x45, 0x41, 0xbc, 0xc8, 0xfe, 0x31, 0x83, 0x11, 0x5a, 0x6b, 0x6c, 0x38, 0x1f, 0x8d,
-	0x3c, 0x48, 0x9e, 0x80, 0x7d, 0x20, 0xf1, 0x47, 0x60, 0x29, 0x12, 0xbf, 0x39, 0xb9, 0x63, 0x73,
-	0x3b, 0xc1, 0x31, 0xc8, 0x4b, 0xf2, 0xb5, 0x2b, 0x2b, 0xda, 0xbb, 0x5c, 0x7c, 0x9b, 0x46, 0xba,
-	0xba, 0x55, 0xf6, 0x50, 0xa9, 0x06, 0x35, 0xc9, 0xd2, 0x9a, 0x46, 0x2b, 0xaa, 0x8e, 0x28, 0x4f,
-	0x4a, 0xcd, 0x97, 0xbe, 0xd5, 0xe7, 0xbf, 0x00, 0x00, 0x00, 0xff, 0xff, 0xfd, 0x0e, 0xcb, 0xab,
-	0x6c, 0x01, 0x00, 0x00,
+	// 1018 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcb, 0x6e, 0xdb, 0x46,
+	0x17, 0x06, 0x45, 0xeb, 0x76, 0x24, 0x39, 0x7f, 0xc6, 0xfe, 0x5b, 0x96, 0x28, 0x12, 0x41, 0x08,
+	0x5a, 0x01, 0x09, 0x24, 0x57, 0x5d, 0xb4, 0x68, 0x16, 0x85, 0x95, 0x1a, 0x6e, 0x51, 0xc0, 0x71,
+	0xc7, 0xd9, 0xb4, 0x40, 0x17, 0x14, 0x79, 0x2c, 0x33, 0x16, 0x2f, 0x9e, 0x19, 0x06, 0xe2, 0xb2,
+	0xab, 0xbe, 0x40, 0x1f, 0xa0, 0xbb, 0x3e, 0x49, 0x9f, 0xa6, 0x2f, 0x51, 0xcc, 0x85, 0x32, 0x49,
+	0xd1, 0x80, 0xd1, 0xdd, 0x9c, 0xcb, 0x7c, 0xe7, 0xcc, 0x77, 0xbe, 0x19, 0x12, 0x8e, 0x70, 0x2b,
+	0xe6, 0x22, 0x4f, 0x91, 0xcf, 0x63, 0x14, 0xb3, 0x94, 0x25, 0x22, 0x21, 0x76, 0x8c, 0xc2, 0xfd,
+	0xff, 0x7d, 0x64, 0xcd, 0xbc, 0xf4, 0x46, 0xc7, 0x26, 0xbf, 0xb7, 0xa1, 0x77, 0x8e, 0xe2, 0x87,
+	0x38, 0xcd, 0x04, 0xf9, 0x1f, 0xd8, 0x19, 0x0b, 0x1d, 0x6b, 0x6c, 0x4d, 0xfb, 0x54, 0x2e, 0xc9,
+	0x0c, 0xba, 0x37, 0xe8, 0x05, 0xc8, 0xb8, 0xd3, 0x1a, 0xdb, 0xd3, 0xc1, 0xe2, 0x78, 0x26, 0x71,
+	0x8b, 0x1d, 0xb3, 0xef, 0x55, 0x90, 0x16, 0x49, 0x64, 0x0e, 0x6d, 0x86, 0x82, 0xe5, 0x8e, 0x3d,
+	0xb6, 0xa6, 0x83, 0xc5, 0x27, 0xd5, 0x6c, 0x2a, 0x43, 0x97, 0xc9, 0x26, 0xf4, 0x73, 0xaa, 0xf3,
+	0xc8, 0x04, 0x86, 0x91, 0xb7, 0x5d, 0x26, 0x41, 0xbe, 0xcc, 0x05, 0x72, 0xe7, 0x60, 0x6c, 0x4d,
+	0x6d, 0x5a, 0xf1, 0x91, 0x67, 0x00, 0x91, 0xb7, 0x7d, 0xc7, 0xc2, 0x74, 0x83, 0xdc, 0x69, 0xab,
+	0x8c, 0x92, 0x87, 0x9c, 0xc0, 0x91, 0xe7, 0xfb, 0x98, 0x0a, 0x0c, 0xae, 0x84, 0x27, 0x32, 0xfe,
+	0x26, 0x09, 0x90, 0x3b, 0x9d, 0xb1, 0x3d, 0x6d, 0xd3, 0xa6, 0x10, 0xf9, 0x02, 0x3a, 0x3c, 0xf5,
+	0xd8, 0xdd, 0xc6, 0xe9, 0x36, 0xf5, 0x79, 0xa5, 0x62, 0x3f, 0x65, 0xc8, 0x72, 0x6a, 0x12, 0xdd,
+	0x13, 0xe8, 0xe8, 0xc3, 0x4a, 0x96, 0x6e, 0x31, 0x2f, 0x58, 0xba, 0xc5, 0x9c, 0x1c, 0x43, 0xfb,
+	0x83, 0xb7, 0xc9, 0xd0, 0x69, 0x29, 0x9f, 0x36, 0xdc, 0x7f, 0x2c, 0x18, 0x94, 0x4e, 0x4c, 0xc6,
+	0x30, 0x88, 0xbc, 0xed, 0xa9, 0x10, 0x18, 0xa5, 0x82, 0xab, 0xfd, 0x6d, 0x5a, 0x76, 0xc9, 0x8c,
+	0x95, 0xe7, 0xdf, 0x26, 0xd7, 0xd7, 0x4b, 0x8f, 0x6b, 0x34, 0x9b, 0x96, 0x5d, 0x92, 0x0a, 0x63,
+	0xbe, 0xf1, 0x52, 0x45, 0xb2, 0x4d, 0x4b, 0x1e, 0xf2, 0x11, 0x74, 0xde, 0x87, 0x42, 0x20, 0x53,
+	0x44, 0x5a, 0xd4, 0x58, 0x64, 0x01, 0xc7, 0x8a, 0x6f, 0x6f, 0xb5, 0xc1, 0x32, 0x47, 0x6d, 0xc5,
+	0x51, 0x63, 0x8c, 0xbc, 0x82, 0xa7, 0x0c, 0x79, 0x8a, 0xbe, 0x50, 0xa7, 0x38, 0xbd, 0x96, 0xb0,
+	0x9d, 0xb1, 0x35, 0xed, 0xd1, 0xfd, 0x80, 0xfb, 0x2d, 0x0c, 0x4a, 0xb4, 0x49, 0x4a, 0xee, 0xe4,
+	0xc2, 0xd0, 0xa4, 0x0d, 0xe2, 0x42, 0x0f, 0x63, 0x3f, 0x09, 0xc2, 0x78, 0x6d, 0xb8, 0xda, 0xd9,
+	0x93, 0x3f, 0x5b, 0xd0, 0xa5, 0x78, 0x97, 0x21, 0x17, 0xf2, 0x18, 0x11, 0x8a, 0x9b, 0x24, 0x30,
+	0xdb, 0x8d, 0x55, 0x08, 0xb4, 0xd5, 0x28, 0x50, 0xfb, 0x31, 0x02, 0x25, 0x70, 0xb0, 0x4a, 0x82,
+	0x5c, 0xd1, 0x33, 0xa4, 0x6a, 0x2d, 0x69, 0xf7, 0x93, 0x58, 0x60, 0x2c, 0xde, 0xe5, 0x29, 0x2a,
+	0x81, 0xf5, 0x69, 0xd9, 0x75, 0x2f, 0xeb, 0xce, 0x7f, 0x94, 0x75, 0xb7, 0x41, 0xd6, 0x0f, 0xc8,
+	0xb6, 0xf7, 0xa0, 0x6c, 0x27, 0x7f, 0xd9, 0xd0, 0x3f, 0x47, 0xf1, 0x36, 0x13, 0xcd, 0xb7, 0xb5,
+	0x38, 0x5c, 0xab, 0x74, 0xb8, 0x67, 0x00, 0x7c, 0x07, 0xa1, 0x14, 0xd3, 0xa6, 0x25, 0x8f, 0x1c,
+	0x14, 0x32, 0x96, 0x68, 0xc1, 0xf4, 0xa9, 0x36, 0x1e, 0x41, 0x89, 0x0b, 0x3d, 0xaf, 0x90, 0x72,
+	0x47, 0xa1, 0xee, 0x6c, 0xf2, 0x02, 0x46, 0x66, 0x7d, 0x26, 0xd1, 0xe4, 0xf1, 0xed, 0x69, 0x9f,
+	0x56, 0x9d, 0xb2, 0xb2, 0xef, 0xf9, 0x37, 0xe8, 0xf4, 0x74, 0x65, 0x65, 0x90, 0x4f, 0xa1, 0xaf,
+	0x5a, 0x50, 0xed, 0xf6, 0x55, 0xe4, 0xde, 0xb1, 0x8b, 0xfe, 0x18, 0xc6, 0x81, 0x03, 0xa5, 0xa8,
+	0x74, 0xc8, 0xe8, 0x4e, 0xc9, 0xce, 0x40, 0x29, 0xf5, 0xde, 0x41, 0xa6, 0xf0, 0xc4, 0x4f, 0xa2,
+	0x94, 0x21, 0xe7, 0x18, 0xe8, 0xb1, 0x0c, 0xd5, 0x58, 0xea, 0x6e, 0xa9, 0xfc, 0x00, 0xeb, 0xb9,
+	0x23, 0x95, 0xbb, 0x1f, 0x90, 0xac, 0xc7, 0xb8, 0x15, 0xce, 0xa1, 0x6a, 0x47, 0xad, 0x27, 0x7f,
+	0xb4, 0x60, 0x78, 0xc9, 0x12, 0x1f, 0x39, 0xd7, 0x4f, 0x6b, 0x31, 0x1a, 0xeb, 0x61, 0xdd, 0xb5,
+	0xf6, 0x49, 0x3e, 0x86, 0xb6, 0x7a, 0xb2, 0x95, 0xb6, 0xfb, 0x54, 0x1b, 0x72, 0xa4, 0x18, 0xac,
+	0xf1, 0xc2, 0x8b, 0xe4, 0x3d, 0xd2, 0x73, 0x2b, 0x79, 0xc8, 0x6b, 0xe8, 0xa5, 0x0c, 0xaf, 0xc3,
+	0xad, 0xb9, 0xe0, 0x83, 0xc5, 0x73, 0x25, 0xd8, 0x72, 0x43, 0xb3, 0x4b, 0x93, 0x71, 0x16, 0x0b,
+	0x96, 0xd3, 0xdd, 0x86, 0xda, 0x63, 0xdb, 0xa9, 0x3f, 0xb6, 0xee, 0x6b, 0x18, 0x55, 0xb6, 0x3e,
+	0xf6, 0x39, 0xfc, 0xa6, 0xf5, 0xb5, 0x35, 0xf9, 0xed, 0x00, 0x46, 0xa6, 0x0b, 0x23, 0xe2, 0x5f,
+	0xe1, 0x48, 0x8a, 0x11, 0x23, 0x8c, 0x05, 0x5f, 0xe6, 0x57, 0xd9, 0xea, 0x3d, 0xfa, 0xc2, 0xb1,
+	0x54, 0xdb, 0x2f, 0xcb, 0x6d, 0xeb, 0x0d, 0xb3, 0xab, 0xfd, 0x6c, 0x7d, 0x84, 0x26, 0x1c, 0x72,
+	0x01, 0x87, 0x92, 0x98, 0x12, 0xb2, 0xfe, 0x8c, 0x7d, 0xd6, 0x80, 0x7c, 0x56, 0x49, 0xd4, 0xa0,
+	0xb5, 0xdd, 0xe4, 0x2b, 0x38, 0xe4, 0x7a, 0xc9, 0x97, 0xb9, 0x9a, 0x9a, 0x7e, 0x75, 0x9e, 0xcc,
+	0xf4, 0x47, 0xd5, 0xe4, 0x71, 0x5a, 0x4b, 0x7b, 0xe0, 0x9a, 0x55, 0xc4, 0xde, 0xae, 0x8b, 0x7d,
+	0x0a, 0x5d, 0x86, 0x3c, 0xdb, 0x98, 0x1b, 0x36, 0x58, 0x1c, 0x9a, 0x2a, 0x54, 0x7b, 0x69, 0x11,
+	0x76, 0x7f, 0x06, 0xe7, 0x21, 0x5e, 0x1a, 0xe6, 0xf3, 0x79, 0x79, 0x3e, 0x83, 0xc5, 0xd3, 0xa2,
+	0xf7, 0x1d, 0x42, 0x69, 0x64, 0xee, 0x5b, 0x38, 0x6a, 0x20, 0xa6, 0x01, 0x75, 0x52, 0x45, 0x1d,
+	0x1a, 0x54, 0xb5, 0xb9, 0xac, 0x81, 0x15, 0x90, 0x73, 0x14, 0xa7, 0x71, 0x50, 0xb9, 0x1f, 0xcf,
+	0xc1, 0x5e, 0xa3, 0x50, 0x78, 0x83, 0xc5, 0xa8, 0xf2, 0xbe, 0x52, 0x19, 0x21, 0x2f, 0xa1, 0x9b,
+	0xea, 0x0d, 0xbb, 0xb6, 0xeb, 0x9a, 0xa6, 0x45, 0xc6, 0x04, 0xe1, 0xa8, 0x52, 0xc3, 0x88, 0x6d,
+	0x5c, 0x2e, 0x72, 0x58, 0x14, 0xd1, 0x41, 0x5d, 0xe5, 0x55, 0xbd, 0x0a, 0xd9, 0x17, 0xca, 0xae,
+	0xcc, 0xe2, 0x6f, 0x0b, 0xec, 0x0b, 0x14, 0xe4, 0x05, 0xd8, 0xe7, 0x28, 0x48, 0xb5, 0x6d, 0xb7,
+	0x56, 0x80, 0x9c, 0x40, 0xd7, 0xe0, 0x90, 0xfd, 0xde, 0xdd, 0x86, 0x42, 0x64, 0x09, 0xa3, 0xca,
+	0x31, 0xc8, 0xc7, 0x05, 0x64, 0x8d, 0x3e, 0xd7, 0xd9, 0x0f, 0x18, 0x8c, 0x09, 0xb4, 0xbe, 0x4b,
+	0xc8, 0x50, 0xc5, 0xcd, 0xe7, 0xb5, 0xde, 0xd9, 0x72, 0xf1, 0xcb, 0xc9, 0x3a, 0x14, 0x37, 0xd9,
+	0x6a, 0xe6, 0x27, 0xd1, 0x3c, 0xbb, 0x4d, 0x3d, 0xb6, 0x09, 0x3d, 0x29, 0x85, 0xf9, 0x9a, 0x25,
+	0x51, 0xec, 0x89, 0xf0, 0x03, 0xce, 0x2b, 0xbf, 0x96, 0xab, 0x8e, 0xfa, 0x7f, 0xfc, 0xf2, 0xdf,
+	0x01, 0x00, 0x1c, 0x61, 0x79, 0x55, 0x72, 0x0a, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// NetClient is the client API for Net service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type NetClient interface {
+	Get(ctx context.Context, in *GetInput, opts ...grpc.CallOption) (*GetOutput, error)
+	Process(ctx context.Context, in *ProcessInput, opts ...grpc.CallOption) (*ProcessOutput, error)
+	GetAndProcess(ctx context.Context, in *GetAndProcessInput, opts ...grpc.CallOption) (*GetAndProcessOutput, error)
+	Do(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetOutput, error)
+}
+
+type netClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNetClient(cc grpc.ClientConnInterface) NetClient {
+	return &netClient{cc}
+}
+
+func (c *netClient) Get(ctx context.Context, in *GetInput, opts ...grpc.CallOption) (*GetOutput, error) {
+	out := new(GetOutput)
+	err := c.cc.Invoke(ctx, "/net.Net/Get", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netClient) Process(ctx context.Context, in *ProcessInput, opts ...grpc.CallOption) (*ProcessOutput, error) {
+	out := new(ProcessOutput)
+	err := c.cc.Invoke(ctx, "/net.Net/Process", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netClient) GetAndProcess(ctx context.Context, in *GetAndProcessInput, opts ...grpc.CallOption) (*GetAndProcessOutput, error) {
+	out := new(GetAndProcessOutput)
+	err := c.cc.Invoke(ctx, "/net.Net/GetAndProcess", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *netClient) Do(ctx context.Context, in *Request, opts ...grpc.CallOption) (*GetOutput, error) {
+	out := new(GetOutput)
+	err := c.cc.Invoke(ctx, "/net.Net/Do", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NetServer is the server API for Net service.
+type NetServer interface {
+	Get(context.Context, *GetInput) (*GetOutput, error)
+	Process(context.Context, *ProcessInput) (*ProcessOutput, error)
+	GetAndProcess(context.Context, *GetAndProcessInput) (*GetAndProcessOutput, error)
+	Do(context.Context, *Request) (*GetOutput, error)
+}
+
+// UnimplementedNetServer can be embedded to have forward compatible implementations.
+type UnimplementedNetServer struct {
+}
+
+func (*UnimplementedNetServer) Get(ctx context.Context, req *GetInput) (*GetOutput, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Get not implemented")
+}
+func (*UnimplementedNetServer) Process(ctx context.Context, req *ProcessInput) (*ProcessOutput, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Process not implemented")
+}
+func (*UnimplementedNetServer) GetAndProcess(ctx context.Context, req *GetAndProcessInput) (*GetAndProcessOutput, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAndProcess not implemented")
+}
+func (*UnimplementedNetServer) Do(ctx context.Context, req *Request) (*GetOutput, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Do not implemented")
+}
+
+func RegisterNetServer(s *grpc.Server, srv NetServer) {
+	s.RegisterService(&_Net_serviceDesc, srv)
+}
+
+func _Net_Get_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInput)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetServer).Get(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Net/Get",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetServer).Get(ctx, req.(*GetInput))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Net_Process_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ProcessInput)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetServer).Process(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Net/Process",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetServer).Process(ctx, req.(*ProcessInput))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Net_GetAndProcess_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAndProcessInput)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetServer).GetAndProcess(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Net/GetAndProcess",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetServer).GetAndProcess(ctx, req.(*GetAndProcessInput))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Net_Do_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NetServer).Do(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/net.Net/Do",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NetServer).Do(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Net_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "net.Net",
+	HandlerType: (*NetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Get",
+			Handler:    _Net_Get_Handler,
+		},
+		{
+			MethodName: "Process",
+			Handler:    _Net_Process_Handler,
+		},
+		{
+			MethodName: "GetAndProcess",
+			Handler:    _Net_GetAndProcess_Handler,
+		},
+		{
+			MethodName: "Do",
+			Handler:    _Net_Do_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ext/types/net.proto",
 }
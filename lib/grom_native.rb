@@ -3,70 +3,329 @@ require 'json'
 require 'grom'
 require 'grom_native/version'
 require 'grom_native/node'
+require 'grom_native/c_string'
+require 'grom_native/errors'
+require 'grom_native/graph_pb'
+require 'grom_native/async_request'
 
 # Top level namespace for our gem
 module GromNative
   extend FFI::Library
   ffi_lib File.expand_path("../ext/gromnative.so", File.dirname(__FILE__))
-  attach_function :get, [:string], :string
+  attach_function :gromnative_free, [:pointer], :void
 
-  def self.fetch(uri:, headers: {}, filter: [], decorators: nil)
-    input = { uri: uri, headers: headers, filter: filter }
-    data_struct = JSON.parse(get(uri))
+  # Pointer-returning variants of every export, freed once read by the wrappers below
+  attach_function :get_pointer, :get, [:string], :pointer
+  attach_function :get_request_pointer, :get_request, [:string], :pointer
+  attach_function :get_request_proto_pointer, :get_request_proto, [:string], :pointer
+  attach_function :get_many_pointer, :get_many, [:string], :pointer
+  attach_function :configure_client_pointer, :configure_client, [:string], :pointer
+  attach_function :cache_stats_pointer, :cache_stats, [], :pointer
+
+  # Exports for requests run in the background, wait_get releases the GVL while it blocks
+  attach_function :start_get, [:string], :long_long
+  attach_function :poll_get_pointer, :poll_get, [:long_long], :pointer
+  attach_function :wait_get_pointer, :wait_get, [:long_long, :long_long], :pointer, blocking: true
+  attach_function :cancel_get, [:long_long], :void
+
+  def self.get(uri)
+    CString.read(get_pointer(uri))
+  end
+
+  def self.get_request(request)
+    CString.read(get_request_pointer(request))
+  end
+
+  # Returns the response as an encoded Graph::Response.
+  def self.get_request_proto(request)
+    CString.read_prefixed(get_request_proto_pointer(request))
+  end
+
+  def self.get_many(batch)
+    CString.read(get_many_pointer(batch))
+  end
+
+  def self.configure_client(config)
+    CString.read(configure_client_pointer(config))
+  end
+
+  def self.cache_stats
+    CString.read(cache_stats_pointer)
+  end
+
+  # Returns the response to a request started with start_get, or nil while it is still running.
+  def self.poll_get(handle)
+    pointer = poll_get_pointer(handle)
+    CString.read(pointer) unless pointer.null?
+  end
+
+  # Waits up to timeout milliseconds, or without limit when negative, for the response to a request started with start_get.
+  # Returns nil if it is still running.
+  def self.wait_get(handle, timeout)
+    pointer = wait_get_pointer(handle, timeout)
+    CString.read(pointer) unless pointer.null?
+  end
+
+  # Configures the HTTP client shared by every fetch. Timeouts are given in seconds,
+  # cache_entries enables a response cache of that size and graph_cache_bytes enables
+  # a cache of processed graphs using up to roughly that much memory.
+  def self.configure(connect_timeout: nil, read_timeout: nil, timeout: nil, proxy: nil, ca_file: nil, cert_file: nil, key_file: nil, max_idle_connections: nil, cache_entries: nil, graph_cache_bytes: nil)
+    config = {
+      connectTimeout:  milliseconds(connect_timeout),
+      readTimeout:     milliseconds(read_timeout),
+      timeout:         milliseconds(timeout),
+      proxyUrl:        proxy,
+      caFile:          ca_file,
+      certFile:        cert_file,
+      keyFile:         key_file,
+      maxIdleConns:    max_idle_connections,
+      cacheEntries:    cache_entries,
+      graphCacheBytes: graph_cache_bytes
+    }.reject { |_, value| value.nil? }
+
+    result = JSON.parse(configure_client(config.to_json))
+
+    raise ArgumentError, result['error'] unless result['error'].to_s.empty?
+
+    self
+  end
+
+  # Returns the processed graph cache statistics: entries, bytes, maxBytes, hits, misses and evictions.
+  def self.graph_cache_stats
+    JSON.parse(cache_stats)
+  end
+
+  def self.milliseconds(seconds)
+    (seconds * 1000).round if seconds
+  end
+
+  # edge_naming picks how edge keys are derived from predicates: 'last_segment' (the default), 'local_name', 'curie' or 'full_uri'.
+  # prefixes maps prefixes to namespaces when using 'curie'.
+  # retries is a hash of retry options, see build_retry.
+  # max_body_bytes and max_triples raise a LimitExceededError for responses over either limit.
+  # accepted_status_codes replaces the status codes treated as success, by default 200, 203, 204 and 304.
+  # paginate: true follows Link rel="next" headers and hydra:next triples, up to max_pages pages (20 by default),
+  # merging every page into one graph.
+  # sparql is a hash with a query and its encoding, see query.
+  # method sends body, with content_type as its Content-Type, in place of a GET, see write.
+  # format: :protobuf passes the graph back as protobuf rather than JSON, which is quicker to decode for large graphs.
+  def self.fetch(uri:, headers: {}, filter: [], decorators: nil, edge_naming: nil, prefixes: nil, retries: nil, max_body_bytes: nil, max_triples: nil, accepted_status_codes: nil, paginate: false, max_pages: nil, sparql: nil, method: nil, body: nil, content_type: nil, format: :json)
+    input = build_request(uri: uri, headers: headers, filter: filter, edge_naming: edge_naming, prefixes: prefixes, retries: retries, max_body_bytes: max_body_bytes, max_triples: max_triples, accepted_status_codes: accepted_status_codes, paginate: paginate, max_pages: max_pages, sparql: sparql, method: method, body: body, content_type: content_type)
+
+    data_struct = if format == :protobuf
+                    proto_struct(Graph::Response.decode(get_request_proto(input.to_json)))
+                  else
+                    JSON.parse(get_request(input.to_json))
+                  end
 
     handle_errors(data_struct)
+    return build_results(data_struct['results']) if data_struct['results']
 
     build_nodes(data_struct, filter, decorators)
   end
 
+  # Posts a SPARQL query to endpoint. CONSTRUCT and DESCRIBE queries return the resulting graph as fetch does,
+  # SELECT queries return an array of hashes of values by variable and ASK queries return true or false.
+  # encoding is 'form' (the default) or 'direct', which posts the query as application/sparql-query.
+  # Any other keyword arguments are passed to fetch.
+  def self.query(endpoint:, query:, encoding: nil, **options)
+    fetch(uri: endpoint, sparql: { query: query, encoding: encoding }, **options)
+  end
+
+  # Sends body to uri with method, such as a PUT of Turtle to the graph API, returning any graph in the response
+  # as fetch does. Failures raise the same errors as fetch, and 201 Created and 202 Accepted are treated as success
+  # unless accepted_status_codes is given. Retries repeat the request, so only pass them when that is harmless.
+  # Any other keyword arguments are passed to fetch.
+  def self.write(uri:, method: 'POST', body: nil, content_type: nil, **options)
+    fetch(uri: uri, method: method, body: body, content_type: content_type, **options)
+  end
+
+  # Starts fetching in the background, taking the same keyword arguments as fetch other than format,
+  # and returns an AsyncRequest to poll, wait on or cancel.
+  def self.start_fetch(uri:, headers: {}, filter: [], decorators: nil, edge_naming: nil, prefixes: nil, retries: nil, max_body_bytes: nil, max_triples: nil, accepted_status_codes: nil, paginate: false, max_pages: nil, sparql: nil, method: nil, body: nil, content_type: nil)
+    input = build_request(uri: uri, headers: headers, filter: filter, edge_naming: edge_naming, prefixes: prefixes, retries: retries, max_body_bytes: max_body_bytes, max_triples: max_triples, accepted_status_codes: accepted_status_codes, paginate: paginate, max_pages: max_pages, sparql: sparql, method: method, body: body, content_type: content_type)
+
+    AsyncRequest.new(start_get(input.to_json), filter, decorators)
+  end
+
+  # Fetches several requests concurrently, each a hash of the keyword arguments taken by fetch.
+  # Results are returned in request order, with a StandardError in place of any request that failed.
+  #
+  # With merge: true every response is merged into a single node graph, built using the filter,
+  # decorators, edge_naming and prefixes given here rather than those on each request.
+  def self.fetch_many(requests, concurrency: nil, merge: false, filter: [], decorators: nil, edge_naming: nil, prefixes: nil, max_triples: nil)
+    inputs = requests.map do |request|
+      build_request(**request.reject { |key, _| key == :decorators })
+    end
+
+    batch = { requests: inputs }
+    batch[:concurrency] = concurrency if concurrency
+
+    if merge
+      batch.merge!(merge: true, filter: filter)
+      batch[:edgeNaming] = edge_naming if edge_naming
+      batch[:prefixes]   = prefixes if prefixes
+      batch[:maxTriples] = max_triples if max_triples
+
+      data_struct = JSON.parse(get_many(batch.to_json))
+
+      handle_errors(data_struct)
+
+      return build_nodes(data_struct, filter, decorators)
+    end
+
+    data_structs = JSON.parse(get_many(batch.to_json))
+    handle_errors(data_structs) if data_structs.is_a?(Hash)
+
+    data_structs.zip(requests).map do |data_struct, request|
+      begin
+        handle_errors(data_struct)
+
+        build_nodes(data_struct, request.fetch(:filter, []), request[:decorators])
+      rescue StandardError => e
+        e
+      end
+    end
+  end
+
+  def self.build_request(uri:, headers: {}, filter: [], edge_naming: nil, prefixes: nil, retries: nil, max_body_bytes: nil, max_triples: nil, accepted_status_codes: nil, paginate: false, max_pages: nil, sparql: nil, method: nil, body: nil, content_type: nil)
+    raise ArgumentError, 'method cannot be combined with paginate' if method && paginate
+    raise ArgumentError, 'method cannot be combined with sparql' if method && sparql
+
+    input = { uri: uri, headers: build_headers(headers), filter: filter }
+    input[:edgeNaming]   = edge_naming if edge_naming
+    input[:prefixes]     = prefixes if prefixes
+    input[:retry]        = build_retry(**retries) if retries
+    input[:maxBodyBytes] = max_body_bytes if max_body_bytes
+    input[:maxTriples]   = max_triples if max_triples
+
+    input[:acceptedStatusCodes] = accepted_status_codes if accepted_status_codes
+    input[:pagination]          = { maxPages: max_pages }.reject { |_, value| value.nil? } if paginate
+    input[:sparql]              = sparql.reject { |_, value| value.nil? } if sparql
+
+    input[:method]      = method.to_s.upcase if method
+    input[:body]        = body if body
+    input[:contentType] = content_type if content_type
+
+    input
+  end
+
+  # Flattens a headers hash into the key/value pairs expected by the Go request envelope.
+  # Multiple values for the same header are sent as separate entries.
+  def self.build_headers(headers)
+    headers.flat_map do |key, values|
+      Array(values).map { |value| { key: key.to_s, value: value.to_s } }
+    end
+  end
+
+  # Builds the retry policy expected by the Go request envelope. Backoff values are given in seconds,
+  # and Retry-After is honoured up to backoff_cap when respect_retry_after is set.
+  def self.build_retry(max_attempts: 3, backoff_base: nil, backoff_cap: nil, jitter: nil, status_codes: nil, respect_retry_after: false)
+    {
+      maxAttempts:          max_attempts,
+      backoffBase:          milliseconds(backoff_base),
+      backoffCap:           milliseconds(backoff_cap),
+      jitter:               jitter,
+      retryableStatusCodes: status_codes,
+      respectRetryAfter:    respect_retry_after
+    }.reject { |_, value| value.nil? }
+  end
+
+  # Raises the GromNative::Error subclass matching the kind of error in data_struct, if there is one.
   def self.handle_errors(data_struct)
-    error = nil
-    status_code = data_struct.fetch('status_code', 0)
+    error = data_struct['error']
+    return if error.nil? || error == ''
+
+    details = data_struct['errorDetails'] || {}
+    status_code = details['statusCode'] || data_struct.fetch('statusCode', 0)
 
     if status_code >= 500
-      error = "Server error: #{data_struct['error']}"
+      raise ServerError.new("Server error: #{error}", details)
     elsif status_code >= 300 && status_code < 500
-      error = "Client error: #{data_struct['error']}"
+      raise ClientError.new("Client error: #{error}", details)
     end
 
-    # require 'irb'; binding.irb
-    error ||= data_struct['error']
+    error_class = ERROR_CLASSES.fetch(details['kind'], Error)
+    error_class = LimitExceededError if data_struct['errorCode']
 
-    raise StandardError, error if error != "" && error != nil
+    raise error_class.new(error, details)
+  end
+
+  # Converts a Graph::Response into the same shape as a parsed JSON response. Triples are left as messages,
+  # which can be read by field name like the hashes in a JSON response.
+  def self.proto_struct(response)
+    graph = response.graph || Graph::Graph.new
+
+    statements_by_subject = {}
+    graph.statementsBySubject.each { |subject, statements| statements_by_subject[subject] = statements.triples }
+
+    edges_by_subject = {}
+    graph.edgesBySubject.each do |subject, edges|
+      edges_by_subject[subject] = {}
+      edges.edges.each { |predicate, objects| edges_by_subject[subject][predicate] = objects.uris }
+    end
+
+    details = response.errorDetails
+    {
+      'statementsBySubject' => statements_by_subject,
+      'edgesBySubject'      => edges_by_subject,
+      'subjectsByType'      => (graph.subjectsByType.map { |subjects| subjects.subjects.to_a } unless graph.subjectsByType.empty?),
+      'statusCode'          => response.statusCode,
+      'uri'                 => response.uri,
+      'error'               => response.error,
+      'errorCode'           => (response.errorCode unless response.errorCode.empty?),
+      'pages'               => response.pages.nonzero?,
+      'results'             => response.results,
+      'errorDetails'        => (details && { 'kind' => details.kind, 'message' => details.message, 'statusCode' => details.statusCode.nonzero?, 'uri' => details.uri, 'retryable' => details.retryable, 'attemptErrors' => details.attemptErrors.to_a })
+    }
+  end
+
+  # Converts a SPARQL result set into an array with a hash of values by variable for each row, leaving out
+  # unbound variables, or the answer to an ASK query into true or false.
+  def self.build_results(results)
+    return results['boolean'] == true if results['ask']
+
+    (results['rows'] || []).map do |row|
+      (row['bindings'] || {}).each_with_object({}) do |(variable, term), values|
+        values[variable] = Node.term_object(term)
+      end
+    end
   end
 
   def self.build_nodes(data_struct, filter, decorators)
     nodes = []
     nodes_by_subject = {}
-    filtered_nodes = Array.new(filter.size) { [] }
 
     data_struct.fetch('statementsBySubject', []).each do |subject, statements|
       node = GromNative::Node.new(statements, decorators)
 
       nodes << node
       nodes_by_subject[subject] = node
-
-      if !filter.empty? && node.respond_to?(:type)
-        node_types = node.blank? ? Array(::Grom::Node::BLANK) : Array(node.type)
-        indexes = node_types.reduce([]) do |memo, type|
-          index = filter.index(type)
-          memo << index if index
-
-          memo
-        end
-
-        indexes.each { |index| filtered_nodes[index] << node }
-      end
     end
 
     link_nodes(nodes_by_subject, data_struct)
 
+    # Subjects have already been grouped by type on the Go side
+    filtered_nodes = data_struct.fetch('subjectsByType', nil) || Array.new(filter.size) { [] }
+    filtered_nodes = filtered_nodes.map do |subjects|
+      subjects.map { |subject| nodes_by_subject[subject] }.compact
+    end
+
     return filtered_nodes.first if filter && filter.size == 1
     return filtered_nodes       if filter
 
     nodes
   end
 
+  # Turns an edge key into an instance variable name. Keys from edge namings other than last_segment may hold
+  # characters Ruby does not allow in names, such as the ':' in rdfs:seeAlso, which become underscores.
+  def self.edge_variable(predicate)
+    name = predicate.gsub(/[^A-Za-z0-9_]/, '_')
+    name = "_#{name}" if name.empty? || name =~ /\A\d/
+
+    "@#{name}".to_sym
+  end
+
   def self.link_nodes(nodes_by_subject, data_struct)
     data_struct.fetch('edgesBySubject', {}).each do |subject, predicates|
       predicates.each do |predicate, object_uris|
@@ -76,7 +335,7 @@ module GromNative
         next if current_node.nil?
 
         object_uris.each do |object_uri|
-          predicate_name_symbol = "@#{predicate}".to_sym
+          predicate_name_symbol = edge_variable(predicate)
 
           # Get the current value (if there is one)
           current_value = current_node.instance_variable_get(predicate_name_symbol)
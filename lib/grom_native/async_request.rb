@@ -0,0 +1,100 @@
+module GromNative
+  # A fetch running in the background, started by GromNative.start_fetch.
+  #
+  # The response is collected once, by poll or wait, after which the nodes are kept on the request.
+  # Requests that are no longer needed, such as when the surrounding Rails request times out, should be
+  # cancelled so the HTTP call and processing are stopped. Requests that are garbage collected before their
+  # response is collected are cancelled then, so their handles are not kept.
+  #
+  # @since 0.2.0
+  #
+  # @example Waiting up to two seconds for a response
+  #   request = GromNative.start_fetch(uri: 'https://api.parliament.uk/query/person_index')
+  #
+  #   begin
+  #     nodes = request.wait(2)
+  #   ensure
+  #     request.cancel unless request.done?
+  #   end
+  class AsyncRequest
+    # @param [Integer] handle the handle returned by start_get.
+    # @param [Array] filter the types to filter the nodes by, as taken by fetch.
+    # @param [Object] decorators the decorators to build the nodes with.
+    def initialize(handle, filter, decorators)
+      @handle     = handle
+      @filter     = filter
+      @decorators = decorators
+      @done       = false
+      @canceled   = false
+
+      ObjectSpace.define_finalizer(self, self.class.finalizer(handle))
+    end
+
+    # Builds the finalizer that cancels a request when it is garbage collected, without holding on to the request.
+    #
+    # @param [Integer] handle the handle returned by start_get.
+    # @return [Proc] the finalizer.
+    def self.finalizer(handle)
+      proc { GromNative.cancel_get(handle) }
+    end
+
+    # @return [Boolean] whether the response has been collected.
+    def done?
+      @done
+    end
+
+    # Returns the nodes if the response is ready, without waiting.
+    #
+    # @raise [GromNative::Error] if the request failed or was cancelled.
+    # @return [Array, nil] the nodes, or nil while the request is still running.
+    def poll
+      collect { GromNative.poll_get(@handle) }
+    end
+
+    # Waits for the response, up to timeout seconds when given.
+    #
+    # @param [Numeric] timeout the longest time to wait in seconds, or nil to wait for as long as it takes.
+    # @raise [GromNative::Error] if the request failed or was cancelled.
+    # @return [Array, nil] the nodes, or nil if the request is still running once the timeout has passed.
+    def wait(timeout = nil)
+      collect { GromNative.wait_get(@handle, timeout ? GromNative.milliseconds(timeout) : -1) }
+    end
+
+    # Stops the request, abandoning its HTTP call and processing. Does nothing once the response has been collected.
+    def cancel
+      return if @done || @canceled
+
+      @canceled = true
+      GromNative.cancel_get(@handle)
+      ObjectSpace.undefine_finalizer(self)
+    end
+
+    private
+
+    def collect
+      raise @error if @error
+      return @nodes if @done
+      raise CanceledError.new('Request was cancelled', 'kind' => 'canceled') if @canceled
+
+      response = yield
+      return if response.nil?
+
+      @done = true
+      ObjectSpace.undefine_finalizer(self)
+
+      data_struct = JSON.parse(response)
+      begin
+        GromNative.handle_errors(data_struct)
+      rescue Error => e
+        @error = e
+        raise
+      end
+
+      @nodes = if data_struct['results']
+                 GromNative.build_results(data_struct['results'])
+               else
+                 GromNative.build_nodes(data_struct, @filter, @decorators)
+               end
+    end
+  end
+end
@@ -0,0 +1,41 @@
+module GromNative
+  # A string allocated by the shared library, released through gromnative_free.
+  #
+  # Every export returns memory owned by the caller, so results are read through this class rather than
+  # attaching the exports with a :string return type, which would copy the string and leak the original.
+  #
+  # @since 0.2.0
+  class CString < FFI::AutoPointer
+    # Called by FFI::AutoPointer when the pointer is freed or garbage collected.
+    #
+    # @param [FFI::Pointer] pointer the string to release.
+    def self.release(pointer)
+      GromNative.gromnative_free(pointer)
+    end
+
+    # Copies the string into Ruby and releases the original straight away.
+    #
+    # @param [FFI::Pointer] pointer a string returned by one of the exports.
+    # @return [String] the UTF-8 string.
+    def self.read(pointer)
+      string = new(pointer)
+
+      string.read_string.force_encoding(Encoding::UTF_8)
+    ensure
+      string&.free
+    end
+
+    # Copies binary data prefixed with its length as a big-endian uint32 into Ruby, and releases the original straight away.
+    #
+    # @param [FFI::Pointer] pointer data returned by one of the protobuf exports.
+    # @return [String] the binary data, without its length.
+    def self.read_prefixed(pointer)
+      data = new(pointer)
+
+      length = data.get_bytes(0, 4).unpack('N').first
+      data.get_bytes(4, length)
+    ensure
+      data&.free
+    end
+  end
+end
@@ -0,0 +1,72 @@
+module GromNative
+  # Base class for errors raised by fetch and fetch_many, built from the structured error returned by the shared library.
+  #
+  # @since 0.2.0
+  #
+  # @attr_reader [String] kind one of network, timeout, canceled, http_status, parse, limit, marshal or invalid_request.
+  # @attr_reader [Integer] status_code the upstream status code, if a response was received.
+  # @attr_reader [String] uri the URI being fetched, if known.
+  # @attr_reader [Array<String>] attempt_errors the error from each failed attempt, in order.
+  class Error < StandardError
+    attr_reader :kind, :status_code, :uri, :attempt_errors
+
+    # @param [String] message the error message.
+    # @param [Hash] details the errorDetails object from a response.
+    def initialize(message = nil, details = {})
+      super(message)
+
+      @kind           = details['kind']
+      @status_code    = details['statusCode']
+      @uri            = details['uri']
+      @retryable      = details['retryable']
+      @attempt_errors = details['attemptErrors'] || []
+    end
+
+    # @return [Boolean] whether another attempt might succeed.
+    def retryable?
+      @retryable == true
+    end
+  end
+
+  # Raised when a connection could not be made or a body could not be read.
+  class NetworkError < Error; end
+
+  # Raised when a connection, or the wait for a response, timed out.
+  class TimeoutError < NetworkError; end
+
+  # Raised when the upstream server responds with a status code that is not accepted. Status codes below 300, which
+  # are only rejected when accepted_status_codes leaves them out, raise this class itself.
+  class HTTPStatusError < Error; end
+
+  # Raised for 5xx status codes.
+  class ServerError < HTTPStatusError; end
+
+  # Raised for 3xx and 4xx status codes.
+  class ClientError < HTTPStatusError; end
+
+  # Raised when a request was cancelled before it finished.
+  class CanceledError < Error; end
+
+  # Raised when a response body could not be decoded.
+  class ParseError < Error; end
+
+  # Raised when a response body or its triple count is over the limit set on the request.
+  class LimitExceededError < Error; end
+
+  # Raised when a request or response could not be converted to or from JSON.
+  class MarshalError < Error; end
+
+  # Raised when a request sets options that cannot be sent together.
+  class InvalidRequestError < Error; end
+
+  ERROR_CLASSES = {
+    'network'         => NetworkError,
+    'timeout'         => TimeoutError,
+    'http_status'     => HTTPStatusError,
+    'canceled'        => CanceledError,
+    'parse'           => ParseError,
+    'limit'           => LimitExceededError,
+    'marshal'         => MarshalError,
+    'invalid_request' => InvalidRequestError
+  }.freeze
+end
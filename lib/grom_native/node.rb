@@ -80,13 +80,7 @@ module GromNative
       set_graph_id(statements)
       statements.each do |statement|
         predicate = Grom::Helper.get_id(statement['predicate']).to_sym
-        object = RDF::NTriples::Reader.parse_object(statement['object'])
-
-        object = if object.is_a? RDF::URI
-                   object.to_s
-                 else
-                   object.object
-                 end
+        object = Node.term_object(statement['object'])
 
         instance_variable = instance_variable_get("@#{predicate}")
 
@@ -101,5 +95,23 @@ module GromNative
         decorators&.decorate_with_type(self, object) if statement['predicate'] == RDF.type && decorators
       end
     end
+
+    # Converts a term to the Ruby value used for it on a node. Terms are hashes in JSON responses
+    # and Graph::Term messages in protobuf ones, a missing kind is an IRI.
+    #
+    # @param [Hash, Graph::Term] term the term.
+    # @return [Object] the IRI, the blank node label prefixed with _: or the literal's Ruby value.
+    def self.term_object(term)
+      case term['kind'].to_s.downcase
+      when BLANK
+        "_:#{term['value']}"
+      when 'literal'
+        datatype = term['datatype'].to_s
+        language = term['language'].to_s
+        RDF::Literal.new(term['value'], datatype: (datatype unless datatype.empty?), language: (language unless language.empty?)).object
+      else
+        term['value']
+      end
+    end
   end
 end
\ No newline at end of file
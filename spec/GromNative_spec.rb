@@ -31,8 +31,343 @@ RSpec.describe GromNative do
 
     context 'with an invalid url' do
       it 'returns the expected object' do
-        expect(JSON.parse(subject.get('foo://a_broken.url'))).to eq({'statementsBySubject' => nil,'edgesBySubject' => nil,'statusCode' => 0,'uri' => '','error' => "Error getting data: Get foo://a_broken.url: unsupported protocol scheme \"foo\"\n"})
+        expect(JSON.parse(subject.get('foo://a_broken.url'))).to eq({'statementsBySubject' => nil,'edgesBySubject' => nil,'statusCode' => 0,'attempts' => 1,'uri' => 'foo://a_broken.url','error' => "Error getting data: Get foo://a_broken.url: unsupported protocol scheme \"foo\"\n",'errorDetails' => {'kind' => 'network','message' => "Get foo://a_broken.url: unsupported protocol scheme \"foo\"",'uri' => 'foo://a_broken.url','retryable' => false,'attemptErrors' => ["Get foo://a_broken.url: unsupported protocol scheme \"foo\""]}})
       end
     end
   end
+
+  describe 'memory ownership' do
+    it 'frees every string returned across repeated calls' do
+      expect(subject).to receive(:gromnative_free).exactly(100).times.and_call_original
+
+      100.times { subject.get('foo://a_broken.url') }
+    end
+
+    it 'does not grow the process while repeatedly fetching' do
+      skip 'needs /proc to measure memory' unless File.exist?('/proc/self/statm')
+
+      request = { uri: 'foo://a_broken.url' }.to_json
+      rss = -> { File.read('/proc/self/statm').split[1].to_i * 4096 }
+
+      1_000.times { subject.get_request(request) }
+      before = rss.call
+      10_000.times { subject.get_request(request) }
+
+      expect(rss.call - before).to be < 8 * 1024 * 1024
+    end
+  end
+
+  describe '.fetch' do
+    context 'with edge naming that does not give valid Ruby names' do
+      it 'links nodes keyed by full URI' do
+        person = subject.fetch(uri: 'http://localhost:3333/linked.nt', filter: ['https://id.parliament.uk/schema/Person'], edge_naming: 'full_uri').first
+
+        expect(person.https___id_parliament_uk_schema_personHasGenderIdentity.first.genderIdentityName).to eq('Female')
+      end
+
+      it 'links nodes keyed by CURIE' do
+        person = subject.fetch(uri: 'http://localhost:3333/linked.nt', filter: ['https://id.parliament.uk/schema/Person'], edge_naming: 'curie', prefixes: { 'schema' => 'https://id.parliament.uk/schema/' }).first
+
+        expect(person.schema_personHasGenderIdentity.first.genderIdentityName).to eq('Female')
+      end
+    end
+  end
+
+  describe '.edge_variable' do
+    it 'replaces characters Ruby does not allow in names' do
+      expect(subject.edge_variable('rdfs:seeAlso')).to eq(:@rdfs_seeAlso)
+      expect(subject.edge_variable('has-part')).to eq(:@has_part)
+      expect(subject.edge_variable('http://example.com/knows')).to eq(:@http___example_com_knows)
+      expect(subject.edge_variable('2019')).to eq(:@_2019)
+    end
+  end
+
+  describe '.get_request' do
+    context 'with an invalid url' do
+      it 'returns the expected object' do
+        request = { uri: 'foo://a_broken.url', headers: [{ key: 'Accept', value: 'application/n-triples' }] }
+
+        expect(JSON.parse(subject.get_request(request.to_json))).to eq({'statementsBySubject' => nil,'edgesBySubject' => nil,'statusCode' => 0,'attempts' => 1,'uri' => 'foo://a_broken.url','error' => "Error getting data: Get foo://a_broken.url: unsupported protocol scheme \"foo\"\n",'errorDetails' => {'kind' => 'network','message' => "Get foo://a_broken.url: unsupported protocol scheme \"foo\"",'uri' => 'foo://a_broken.url','retryable' => false,'attemptErrors' => ["Get foo://a_broken.url: unsupported protocol scheme \"foo\""]}})
+      end
+    end
+  end
+
+  describe '.get_request_proto' do
+    it 'returns an encoded response' do
+      response = Graph::Response.decode(subject.get_request_proto({ uri: 'foo://a_broken.url' }.to_json))
+
+      expect(response.error).to eq("Error getting data: Get foo://a_broken.url: unsupported protocol scheme \"foo\"\n")
+      expect(response.errorDetails.kind).to eq('network')
+    end
+
+    it 'frees the returned data' do
+      expect(subject).to receive(:gromnative_free).once.and_call_original
+
+      subject.get_request_proto({ uri: 'foo://a_broken.url' }.to_json)
+    end
+  end
+
+  describe '.proto_struct' do
+    it 'matches the shape of a JSON response' do
+      triple = Graph::Triple.new(subject: 'https://id.parliament.uk/1', predicate: 'https://id.parliament.uk/schema/name', object: Graph::Term.new(kind: :LITERAL, value: 'A', datatype: 'http://www.w3.org/2001/XMLSchema#string'))
+      response = Graph::Response.new(
+        graph: Graph::Graph.new(
+          statementsBySubject: { 'https://id.parliament.uk/1' => Graph::Statements.new(triples: [triple]) },
+          edgesBySubject: { 'https://id.parliament.uk/1' => Graph::Edges.new(edges: { 'name' => Graph::Objects.new(uris: ['https://id.parliament.uk/2']) }) }
+        ),
+        statusCode: 200
+      )
+
+      data_struct = subject.proto_struct(response)
+
+      expect(data_struct['statementsBySubject']['https://id.parliament.uk/1'].first['object']['value']).to eq('A')
+      expect(data_struct['edgesBySubject']).to eq('https://id.parliament.uk/1' => { 'name' => ['https://id.parliament.uk/2'] })
+      expect(data_struct['subjectsByType']).to be_nil
+      expect(data_struct['errorCode']).to be_nil
+    end
+  end
+
+  describe '.get_many' do
+    it 'returns a response for each request in order' do
+      batch = { requests: [{ uri: 'foo://a_broken.url' }, { uri: 'bar://a_broken.url' }], concurrency: 2 }
+
+      expect(JSON.parse(subject.get_many(batch.to_json)).map { |response| response['uri'] }).to eq(['foo://a_broken.url', 'bar://a_broken.url'])
+    end
+  end
+
+  describe '.fetch_many' do
+    it 'returns an error in place of a failed request' do
+      results = subject.fetch_many([{ uri: 'foo://a_broken.url' }])
+
+      expect(results.first).to be_a(StandardError)
+      expect(results.first.message).to match(/unsupported protocol scheme "foo"/)
+    end
+
+    context 'when merging' do
+      it 'raises the first error' do
+        expect { subject.fetch_many([{ uri: 'foo://a_broken.url' }], merge: true) }.to raise_error(GromNative::NetworkError, /unsupported protocol scheme "foo"/)
+      end
+    end
+  end
+
+  describe '.start_fetch' do
+    it 'returns the nodes once the request finishes' do
+      request = subject.start_fetch(uri: 'http://localhost:3333/full.nt')
+
+      expect(request.wait(5)).not_to be_empty
+      expect(request).to be_done
+      expect(request.poll).to eq(request.wait)
+    end
+
+    it 'raises the error from a failed request' do
+      request = subject.start_fetch(uri: 'foo://a_broken.url')
+
+      expect { request.wait }.to raise_error(GromNative::NetworkError, /unsupported protocol scheme "foo"/)
+      expect { request.poll }.to raise_error(GromNative::NetworkError)
+    end
+
+    it 'raises a CanceledError once cancelled' do
+      request = subject.start_fetch(uri: 'http://localhost:3333/full.nt')
+      request.cancel
+
+      expect { request.wait }.to raise_error(GromNative::CanceledError)
+    end
+
+    it 'cancels a request that is garbage collected before its response is collected' do
+      handle = subject.start_get({ uri: 'http://localhost:3333/full.nt' }.to_json)
+      GromNative::AsyncRequest.finalizer(handle).call
+
+      expect(JSON.parse(subject.poll_get(handle))['error']).to match(/Unknown request handle #{handle}/)
+    end
+
+    it 'keeps the request from being cancelled once its response is collected' do
+      request = subject.start_fetch(uri: 'http://localhost:3333/full.nt')
+      expect(ObjectSpace).to receive(:undefine_finalizer).with(request).and_call_original
+
+      request.wait(5)
+    end
+  end
+
+  describe '.start_get' do
+    it 'returns a marshal error for a request that cannot be parsed' do
+      handle = subject.start_get('not json')
+
+      expect(JSON.parse(subject.wait_get(handle, -1))['errorDetails']['kind']).to eq('marshal')
+    end
+  end
+
+  describe '.poll_get' do
+    it 'returns an error for an unknown handle' do
+      expect(JSON.parse(subject.poll_get(-1))['error']).to match(/Unknown request handle -1/)
+    end
+  end
+
+  describe '.query' do
+    it 'posts the query to the endpoint' do
+      expect(subject).to receive(:fetch).with(uri: 'http://localhost:7200/repositories/parliament', sparql: { query: 'DESCRIBE <http://example.com/1>', encoding: 'direct' }, filter: ['http://example.com/Person'])
+
+      subject.query(endpoint: 'http://localhost:7200/repositories/parliament', query: 'DESCRIBE <http://example.com/1>', encoding: 'direct', filter: ['http://example.com/Person'])
+    end
+  end
+
+  describe '.write' do
+    it 'sends the body with the method' do
+      expect(subject).to receive(:fetch).with(uri: 'https://api.parliament.uk/graph/1', method: 'PUT', body: '<http://example.com/1> a <http://example.com/Person> .', content_type: 'application/n-triples', retries: { max_attempts: 2 })
+
+      subject.write(uri: 'https://api.parliament.uk/graph/1', method: 'PUT', body: '<http://example.com/1> a <http://example.com/Person> .', content_type: 'application/n-triples', retries: { max_attempts: 2 })
+    end
+  end
+
+  describe '.build_results' do
+    it 'returns a hash of values by variable for each row' do
+      results = {
+        'variables' => %w[person name],
+        'rows'      => [
+          { 'bindings' => { 'person' => { 'value' => 'https://id.parliament.uk/1' }, 'name' => { 'kind' => 'LITERAL', 'value' => 'A' } } },
+          { 'bindings' => { 'person' => { 'value' => 'https://id.parliament.uk/2' } } }
+        ]
+      }
+
+      expect(subject.build_results(results)).to eq([{ 'person' => 'https://id.parliament.uk/1', 'name' => 'A' }, { 'person' => 'https://id.parliament.uk/2' }])
+    end
+
+    it 'returns the answer to an ASK query' do
+      expect(subject.build_results('ask' => true, 'boolean' => true)).to be(true)
+      expect(subject.build_results('ask' => true)).to be(false)
+    end
+
+    it 'reads protobuf results' do
+      results = Graph::Results.new(variables: ['count'], rows: [Graph::Results::Row.new(bindings: { 'count' => Graph::Term.new(kind: :LITERAL, value: '3', datatype: 'http://www.w3.org/2001/XMLSchema#integer') })])
+
+      expect(subject.build_results(results)).to eq([{ 'count' => 3 }])
+    end
+  end
+
+  describe '.configure' do
+    after { subject.configure }
+
+    it 'accepts timeouts in seconds' do
+      expect(subject.configure(connect_timeout: 1, read_timeout: 2.5, timeout: 10, max_idle_connections: 4)).to eq(subject)
+    end
+
+    it 'enables the graph cache' do
+      subject.configure(graph_cache_bytes: 1024)
+
+      expect(subject.graph_cache_stats).to include('maxBytes' => 1024, 'entries' => 0)
+    end
+
+    it 'raises an error for a missing CA file' do
+      expect { subject.configure(ca_file: '/no/such/file.pem') }.to raise_error(ArgumentError, /Error configuring client: Error reading CA file/)
+    end
+  end
+
+  describe '.handle_errors' do
+    it 'does nothing without an error' do
+      expect { subject.handle_errors('error' => '', 'statusCode' => 200) }.not_to raise_error
+    end
+
+    it 'raises a LimitExceededError for responses over a limit' do
+      data_struct = { 'error' => 'Found more than the limit of 10 triples', 'errorCode' => 'too_many_triples' }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::LimitExceededError, /limit of 10 triples/)
+    end
+
+    it 'raises a ServerError for 5xx status codes' do
+      data_struct = { 'error' => 'Received 503 status code', 'errorDetails' => { 'kind' => 'http_status', 'statusCode' => 503, 'uri' => 'http://example.com', 'retryable' => true } }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::ServerError, 'Server error: Received 503 status code') { |error|
+        expect(error.status_code).to eq(503)
+        expect(error.uri).to eq('http://example.com')
+        expect(error).to be_retryable
+      }
+    end
+
+    it 'gives the error from each attempt' do
+      data_struct = { 'error' => 'Received 503 status code', 'errorDetails' => { 'kind' => 'http_status', 'statusCode' => 503, 'attemptErrors' => ['Received 503 status code', 'Received 503 status code'] } }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::ServerError) { |error|
+        expect(error.attempt_errors).to eq(['Received 503 status code', 'Received 503 status code'])
+      }
+    end
+
+    it 'raises a ClientError for 4xx status codes' do
+      data_struct = { 'error' => 'Received 404 status code', 'statusCode' => 404 }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::ClientError, 'Client error: Received 404 status code')
+    end
+
+    it 'raises an HTTPStatusError for status codes left out of the accepted status codes' do
+      data_struct = { 'error' => 'Received 200 status code', 'errorDetails' => { 'kind' => 'http_status', 'statusCode' => 200 } }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::HTTPStatusError, 'Received 200 status code') { |error|
+        expect(error).not_to be_a(GromNative::ServerError)
+        expect(error).not_to be_a(GromNative::ClientError)
+        expect(error.status_code).to eq(200)
+      }
+    end
+
+    it 'raises an InvalidRequestError for options that cannot be sent together' do
+      data_struct = { 'error' => 'Method POST cannot be combined with pagination', 'errorDetails' => { 'kind' => 'invalid_request', 'uri' => 'http://example.com' } }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::InvalidRequestError, 'Method POST cannot be combined with pagination')
+    end
+
+    it 'raises an error matching the kind' do
+      data_struct = { 'error' => 'Timed out', 'errorDetails' => { 'kind' => 'timeout', 'retryable' => true } }
+
+      expect { subject.handle_errors(data_struct) }.to raise_error(GromNative::TimeoutError) { |error| expect(error.kind).to eq('timeout') }
+    end
+  end
+
+  describe '.build_request' do
+    it 'includes limits when given' do
+      expect(subject.build_request(uri: 'http://example.com', max_body_bytes: 1024, max_triples: 10)).to include(maxBodyBytes: 1024, maxTriples: 10)
+    end
+
+    it 'includes accepted status codes when given' do
+      expect(subject.build_request(uri: 'http://example.com', accepted_status_codes: [200, 404])).to include(acceptedStatusCodes: [200, 404])
+    end
+
+    it 'includes pagination when paginating' do
+      expect(subject.build_request(uri: 'http://example.com', paginate: true, max_pages: 5)).to include(pagination: { maxPages: 5 })
+      expect(subject.build_request(uri: 'http://example.com', paginate: true)).to include(pagination: {})
+      expect(subject.build_request(uri: 'http://example.com')).not_to include(:pagination)
+    end
+
+    it 'includes a SPARQL query when given' do
+      expect(subject.build_request(uri: 'http://example.com', sparql: { query: 'DESCRIBE <http://example.com/1>', encoding: nil })).to include(sparql: { query: 'DESCRIBE <http://example.com/1>' })
+    end
+
+    it 'includes the method and body when given' do
+      expect(subject.build_request(uri: 'http://example.com', method: :put, body: '<http://example.com/1> a <http://example.com/Person> .', content_type: 'application/n-triples')).to include(
+        method: 'PUT', body: '<http://example.com/1> a <http://example.com/Person> .', contentType: 'application/n-triples'
+      )
+      expect(subject.build_request(uri: 'http://example.com')).not_to include(:method, :body, :contentType)
+    end
+
+    it 'rejects a method combined with pagination or a SPARQL query' do
+      expect { subject.build_request(uri: 'http://example.com', method: :post, paginate: true) }.to raise_error(ArgumentError, /paginate/)
+      expect { subject.build_request(uri: 'http://example.com', method: :post, sparql: { query: 'DESCRIBE <http://example.com/1>' }) }.to raise_error(ArgumentError, /sparql/)
+    end
+  end
+
+  describe '.build_retry' do
+    it 'converts backoff values to milliseconds' do
+      expect(subject.build_retry(max_attempts: 5, backoff_base: 0.25, backoff_cap: 2, status_codes: [503])).to eq(
+        maxAttempts: 5, backoffBase: 250, backoffCap: 2000, retryableStatusCodes: [503], respectRetryAfter: false
+      )
+    end
+  end
+
+  describe '.build_headers' do
+    it 'flattens multiple values into separate headers' do
+      headers = { 'Accept' => ['*/*', 'application/n-triples'], 'Api-Access-Key' => '12345678' }
+
+      expect(subject.build_headers(headers)).to eq([
+        { key: 'Accept', value: '*/*' },
+        { key: 'Accept', value: 'application/n-triples' },
+        { key: 'Api-Access-Key', value: '12345678' }
+      ])
+    end
+  end
 end